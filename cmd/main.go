@@ -6,18 +6,19 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
-	"path/filepath"
 	"syscall"
 	"time"
 
 	"github.com/sentnl/inferoute-node/inferoute-client/internal/config"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/compat"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/energy"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/health"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/pricing"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/server"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/state"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
 	"go.uber.org/zap"
 )
@@ -35,10 +36,13 @@ Inferoute Client - A client for connecting to the Inferoute network
 Usage:
   inferoute-client [flags]
   inferoute-client compatibility [flags]
+  inferoute-client pricing advise [flags]
 
 Commands:
   compatibility   Detect local hardware and list which approved models can run
                   (does not start the provider daemon)
+  pricing advise  Benchmark local models and compare break-even prices with
+                  platform averages (does not start the provider daemon)
 
 Flags:
   --config string   Path to configuration file (default: ~/.config/inferoute/config.yaml)
@@ -57,6 +61,13 @@ func main() {
 		}
 		return
 	}
+	if len(os.Args) > 1 && os.Args[1] == "pricing" {
+		if err := runPricing(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "pricing: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
 
 	// Create custom flag set
 	flags := flag.NewFlagSet(os.Args[0], flag.ExitOnError)
@@ -90,33 +101,12 @@ func main() {
 
 	// If no config path is provided, check standard locations
 	if *configPath == "" {
-		// Get user's home directory
-		homeDir, err := os.UserHomeDir()
+		location, err := config.Locate()
 		if err != nil {
-			fmt.Printf("Failed to get user home directory: %v\n", err)
-			os.Exit(1)
-		}
-
-		// Check standard config locations
-		configLocations := []string{
-			filepath.Join(homeDir, ".config", "inferoute", "config.yaml"),
-			"config.yaml", // Current directory
-		}
-
-		for _, location := range configLocations {
-			if _, err := os.Stat(location); err == nil {
-				*configPath = location
-				break
-			}
-		}
-
-		if *configPath == "" {
-			fmt.Printf("No configuration file found in standard locations:\n")
-			for _, location := range configLocations {
-				fmt.Printf("  - %s\n", location)
-			}
+			fmt.Printf("%v\n", err)
 			os.Exit(1)
 		}
+		*configPath = location
 	}
 
 	// Load configuration
@@ -144,17 +134,31 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Keep registrations, counters, verification caches and the tunnel across restarts
+	store, err := state.Open(cfg.State.Dir)
+	if err != nil {
+		logger.Warn("Persistent state disabled", zap.Error(err))
+	}
+
+	// Initialize LLM client
+	llmClient := llm.NewClient(cfg.Provider.ProviderType, cfg.Provider.LLMURL)
+
 	// Initialize GPU monitor
 	gpuMonitor, err := gpu.NewMonitor()
 	if err != nil {
 		logger.Error("Failed to initialize GPU monitor", zap.Error(err))
 		// Continue without GPU monitoring instead of exiting
 		logger.Warn("Continuing without GPU monitoring")
+	} else {
+		// Sample in the background so request handlers and the console never fork nvidia-smi
+		gpuMonitor.SetDevices(cfg.GPU.Devices)
+		gpuMonitor.SetProtection(cfg.GPU.Protection)
+		if cfg.GPU.OwnerPriority.Enabled {
+			gpuMonitor.SetOwnerPriority(cfg.GPU.OwnerPriority, ownerYieldHook(ctx, cfg, llmClient))
+		}
+		gpuMonitor.Start(ctx, cfg.GPU.SampleInterval, cfg.GPU.UtilizationSmoothing)
 	}
 
-	// Initialize LLM client
-	llmClient := llm.NewClient(cfg.Provider.ProviderType, cfg.Provider.LLMURL)
-
 	// Initialize pricing client
 	pricingClient := pricing.NewClient(cfg.Provider.URL, cfg.Provider.APIKey)
 
@@ -167,24 +171,72 @@ func main() {
 	}
 	serverClient := verify.NewServerClient(cfg.Provider.URL, cfg.Provider.APIKey)
 	modelVerifier := verify.NewVerifier(catalog, serverClient, cfg.Provider.ProviderType, cfg.Provider.HFHubCache, cfg.Provider.ModelPath)
+	modelVerifier.SetHashWorkers(cfg.Verification.HashWorkers)
+	modelVerifier.SetOllamaModels(cfg.Provider.OllamaModels)
+	// Run canary prompts on the backend and submit their output hashes with
+	// each verification
+	modelVerifier.EnableAttestation(llmClient, cfg.Verification.Canaries, cfg.Verification.AttestInterval)
+	state.Attach(store, "verifier", modelVerifier.RestoreState, modelVerifier.State)
+	// Re-verify models as soon as their files change; watches are added as
+	// models are verified, starting with the reconcile below
+	if cfg.Verification.Watch {
+		modelVerifier.StartWatching(ctx, llmClient, cfg.Verification.PollInterval)
+	}
+	// Inference is gated on an allowlist kept current in the background; the
+	// reconcile below fills it before the server starts
+	modelVerifier.StartWorker(ctx, llmClient, cfg.Verification.RefreshInterval)
+
+	// Register verified local models at policy prices; later health cycles keep them in sync
+	reconciler := pricing.NewReconciler(pricingClient, cfg.Provider.ProviderType, cfg.Pricing.Policy)
+	state.Attach(store, "registrations", reconciler.RestoreRegistrations, reconciler.Registrations)
+
+	// Move prices with the schedule and admission load; resume the last factor
+	// before the first reconcile so a restart does not reset prices
+	var pricingEngine *pricing.Engine
+	if cfg.Pricing.Dynamic.Enabled {
+		pricingEngine, err = pricing.NewEngine(cfg.Pricing.Dynamic, reconciler)
+		if err != nil {
+			logger.Fatal("Failed to start dynamic pricing", zap.Error(err))
+		}
+		state.Attach(store, "dynamic_pricing", pricingEngine.RestoreState, pricingEngine.State)
+	}
 
-	// Register local models with pricing
-	var registeredModelIDs []string
-	if ids, err := pricing.RegisterLocalModels(ctx, llmClient, pricingClient, cfg.Provider.ProviderType, modelVerifier); err != nil {
+	if _, err := reconciler.ReconcileLocal(ctx, llmClient, modelVerifier); err != nil {
 		logger.Error("Failed to register local models", zap.Error(err))
-	} else {
-		registeredModelIDs = ids
 	}
 
+	// Meter GPU energy per request; without a GPU monitor only tokens are counted
+	var energySource func() float64
+	if gpuMonitor != nil {
+		energySource = gpuMonitor.EnergyJoules
+	}
+	energyMeter := energy.NewMeter(energySource, cfg.Energy)
+	energyMeter.SetPriceLookup(registeredPrices(reconciler))
+	state.Attach(store, "energy", energyMeter.RestoreTotals, energyMeter.Totals)
+
 	// Initialize health reporter
 	healthReporter := health.NewReporter(cfg, gpuMonitor, llmClient)
 	healthReporter.SetVerifier(modelVerifier)
-
-	// Initialize the registered models tracker
-	healthReporter.InitializeRegisteredModels(registeredModelIDs)
+	healthReporter.SetEnergyMeter(energyMeter)
+	healthReporter.SetReconciler(reconciler)
 
 	// Initialize and start HTTP server (which sets up Cloudflare tunnel)
 	srv := server.CreateServer(cfg, gpuMonitor, healthReporter, modelVerifier)
+	srv.SetEnergyMeter(energyMeter)
+	state.Attach(store, "requests", srv.RestoreRequestStats, srv.RequestStats)
+	if cf := srv.GetCloudflareClient(); cf != nil {
+		state.Attach(store, "tunnel", cf.RestoreState, cf.State)
+	}
+	store.Start(ctx, cfg.State.FlushInterval)
+
+	if pricingEngine != nil {
+		pricingEngine.SetLoad(srv.Load)
+		pricingEngine.Start(ctx)
+		logger.Info("Dynamic pricing enabled",
+			zap.Bool("dry_run", cfg.Pricing.Dynamic.DryRun),
+			zap.Int("schedule_windows", len(cfg.Pricing.Dynamic.Schedule)),
+			zap.Int("utilization_bands", len(cfg.Pricing.Dynamic.Utilization)))
+	}
 
 	// Start server in background and wait for Cloudflare tunnel to be ready
 	serverReady := make(chan error, 1)
@@ -254,4 +306,36 @@ func main() {
 	if err := srv.Stop(ctx); err != nil {
 		logger.Fatal("Server shutdown failed", zap.Error(err))
 	}
+	if err := store.Flush(); err != nil {
+		logger.Warn("Failed to save persistent state", zap.Error(err))
+	}
+}
+
+// ownerYieldHook returns the callback run when the GPU is yielded to its owner:
+// with gpu.owner_priority.unload_ollama it frees VRAM by unloading Ollama models.
+func ownerYieldHook(ctx context.Context, cfg *config.Config, llmClient llm.Client) func() {
+	ollama, ok := llmClient.(*llm.OllamaClient)
+	if !cfg.GPU.OwnerPriority.UnloadOllama || !ok {
+		return nil
+	}
+	return func() {
+		unloadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+		defer cancel()
+		if err := ollama.UnloadModels(unloadCtx); err != nil {
+			logger.Warn("Failed to unload Ollama models for GPU owner", zap.Error(err))
+		}
+	}
+}
+
+// registeredPrices estimates earnings from the prices models are registered at
+// on the platform, which are per token; the meter wants per-million-token prices.
+// Models not registered by this node earn nothing in the estimate.
+func registeredPrices(reconciler *pricing.Reconciler) energy.PriceLookup {
+	return func(model string) (float64, float64, bool) {
+		prices, ok := reconciler.RegisteredPrices(model)
+		if !ok {
+			return 0, 0, false
+		}
+		return prices.Input * 1e6, prices.Output * 1e6, true
+	}
 }
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/internal/config"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/pricing"
+	"go.uber.org/zap"
+)
+
+const pricingHelp = `Usage:
+  inferoute-client pricing advise [flags]
+
+Benchmark each local model on the LLM backend, measure GPU power while it runs,
+and compute the input and output prices at which serving it covers electricity
+and hardware amortization. Break-even prices are compared with the platform
+averages. Does not start the provider daemon or register anything.
+
+Flags:
+  --config string              Path to configuration file (default: standard locations)
+  --models list                Comma-separated models to benchmark (default: all local models)
+  --price-per-kwh float        Electricity price in USD (default: energy.price_per_kwh)
+  --hardware-cost float        Hardware cost in USD (default: pricing.advisor.hardware_cost)
+  --amortization-months float  Months to write the hardware off over (default: pricing.advisor.amortization_months)
+  --utilization float          Expected share of time spent serving, 0-1 (default: pricing.advisor.utilization)
+  --watts float                Power draw to assume instead of measuring it
+  --max-tokens int             Tokens generated per output benchmark (default: 256)
+  --json                       Emit machine-readable JSON
+  --help                       Show this help
+`
+
+// runPricing dispatches `inferoute-client pricing <subcommand>`.
+func runPricing(args []string) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "--help" || args[0] == "-h" {
+		fmt.Fprint(os.Stderr, pricingHelp)
+		return nil
+	}
+	if args[0] != "advise" {
+		return fmt.Errorf("unknown subcommand: %s", args[0])
+	}
+	return runPricingAdvise(args[1:])
+}
+
+func runPricingAdvise(args []string) error {
+	fs := flag.NewFlagSet("pricing advise", flag.ContinueOnError)
+	fs.SetOutput(os.Stderr)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, pricingHelp)
+	}
+
+	configPath := fs.String("config", "", "Path to configuration file")
+	models := fs.String("models", "", "Comma-separated models to benchmark")
+	pricePerKWh := fs.Float64("price-per-kwh", -1, "Electricity price in USD per kWh")
+	hardwareCost := fs.Float64("hardware-cost", -1, "Hardware cost in USD")
+	amortizationMonths := fs.Float64("amortization-months", -1, "Months to write the hardware off over")
+	utilization := fs.Float64("utilization", -1, "Expected share of time spent serving (0-1)")
+	watts := fs.Float64("watts", 0, "Power draw to assume instead of measuring it")
+	maxTokens := fs.Int("max-tokens", pricing.DefaultBenchmarkTokens, "Tokens generated per output benchmark")
+	jsonOut := fs.Bool("json", false, "Emit JSON output")
+
+	if err := fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument: %s", fs.Arg(0))
+	}
+
+	if *configPath == "" {
+		location, err := config.Locate()
+		if err != nil {
+			return err
+		}
+		*configPath = location
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	// Keep the report on stdout; the daemon's log files are not needed here.
+	logger.SetDefaultLogger(&logger.Logger{Logger: zap.NewNop()})
+
+	costs := pricing.Costs{PricePerKWh: cfg.Energy.PricePerKWh, Hardware: cfg.Pricing.Advisor}
+	if *pricePerKWh >= 0 {
+		costs.PricePerKWh = *pricePerKWh
+	}
+	if *hardwareCost >= 0 {
+		costs.Hardware.HardwareCost = *hardwareCost
+	}
+	if *amortizationMonths >= 0 {
+		costs.Hardware.AmortizationMonths = *amortizationMonths
+	}
+	if *utilization >= 0 {
+		costs.Hardware.Utilization = *utilization
+	}
+	if costs.Hardware.Utilization > 1 {
+		return fmt.Errorf("--utilization must be between 0 and 1")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	llmClient := llm.NewClient(cfg.Provider.ProviderType, cfg.Provider.LLMURL)
+	names, err := benchmarkModels(ctx, llmClient, *models)
+	if err != nil {
+		return err
+	}
+
+	energy, err := powerSource(ctx, cfg, *watts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; energy cost is left out (pass --watts to assume a draw)\n", err)
+	}
+
+	throughputs := make([]pricing.Throughput, 0, len(names))
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "benchmarking %s...\n", name)
+		t, err := pricing.Benchmark(ctx, llmClient, name, *maxTokens, energy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: skipping %s: %v\n", name, err)
+			continue
+		}
+		if *watts > 0 {
+			t.PromptWatts, t.OutputWatts = *watts, *watts
+		}
+		throughputs = append(throughputs, t)
+	}
+	if len(throughputs) == 0 {
+		return fmt.Errorf("no model could be benchmarked")
+	}
+
+	prices, err := pricing.NewClient(cfg.Provider.URL, cfg.Provider.APIKey).GetModelPrices(ctx, names)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to get platform prices: %v\n", err)
+		prices = nil
+	}
+
+	advice := pricing.Advise(throughputs, prices, costs)
+	if *jsonOut {
+		return pricing.WriteAdviceJSON(os.Stdout, advice)
+	}
+	return pricing.WriteAdviceTable(os.Stdout, advice)
+}
+
+// benchmarkModels returns the models named in list, or every local model.
+func benchmarkModels(ctx context.Context, llmClient llm.Client, list string) ([]string, error) {
+	var names []string
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		return names, nil
+	}
+
+	models, err := llmClient.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local models: %w", err)
+	}
+	for _, m := range models.Models {
+		names = append(names, m.ID)
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no local models found")
+	}
+	return names, nil
+}
+
+// powerSource starts a fast GPU sampler on the backend's devices and returns
+// its joule counter. With an assumed draw no sampler is needed.
+func powerSource(ctx context.Context, cfg *config.Config, watts float64) (func() float64, error) {
+	if watts > 0 {
+		return nil, nil
+	}
+	monitor, err := gpu.NewMonitor()
+	if err != nil {
+		return nil, fmt.Errorf("no GPU power readings: %w", err)
+	}
+	monitor.SetDevices(cfg.GPU.Devices)
+	monitor.Start(ctx, time.Second, 0)
+	info, err := monitor.Snapshot()
+	if err != nil || info.PowerDraw == 0 {
+		return nil, fmt.Errorf("GPU does not report power draw")
+	}
+	return monitor.EnergyJoules, nil
+}
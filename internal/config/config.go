@@ -4,8 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/energy"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/pricing"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/state"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
 	"gopkg.in/yaml.v3"
 )
 
@@ -13,8 +19,11 @@ import (
 type Config struct {
 	// Server configuration
 	Server struct {
-		Port int    `yaml:"port"`
-		Host string `yaml:"host"`
+		Port        int             `yaml:"port"`
+		Host        string          `yaml:"host"`
+		ReadTimeout time.Duration   `yaml:"read_timeout"`
+		Deadlines   DeadlinesConfig `yaml:"deadlines"`
+		Admission   AdmissionConfig `yaml:"admission"`
 	} `yaml:"server"`
 
 	// Provider configuration
@@ -23,12 +32,90 @@ type Config struct {
 		URL               string `yaml:"url"`
 		ProviderType      string `yaml:"provider_type"`
 		LLMURL            string `yaml:"llm_url"`
-		HFHubCache        string `yaml:"hf_hub_cache"` // optional; default ~/.cache/huggingface/hub
-		ModelPath         string `yaml:"model_path"`   // optional flat dir override (hf download --local-dir)
+		HFHubCache        string `yaml:"hf_hub_cache"`  // optional; default ~/.cache/huggingface/hub
+		ModelPath         string `yaml:"model_path"`    // optional flat dir override (hf download --local-dir)
+		OllamaModels      string `yaml:"ollama_models"` // optional; default $OLLAMA_MODELS or ~/.ollama/models
 	} `yaml:"provider"`
 
+	// GPU monitoring configuration
+	GPU struct {
+		SampleInterval       time.Duration           `yaml:"sample_interval"`
+		UtilizationSmoothing float64                 `yaml:"utilization_smoothing"` // EWMA weight of each new sample (0-1]
+		Devices              []string                `yaml:"devices"`               // GPUs the backend uses (index or UUID); empty = all
+		Protection           gpu.ProtectionConfig    `yaml:"protection"`
+		OwnerPriority        gpu.OwnerPriorityConfig `yaml:"owner_priority"`
+	} `yaml:"gpu"`
+
+	// Energy metering configuration
+	Energy energy.Config `yaml:"energy"`
+
+	// Pricing configuration
+	Pricing struct {
+		Policy  pricing.Policy        `yaml:",inline"` // markup, floor, ceiling and per-model prices
+		Advisor pricing.AdvisorConfig `yaml:"advisor"` // running costs for `pricing advise`
+		Dynamic pricing.DynamicConfig `yaml:"dynamic"` // schedule and load multipliers
+	} `yaml:"pricing"`
+
+	// Weight verification configuration
+	Verification verify.Config `yaml:"verification"`
+
 	// Logging configuration
 	Logging logger.Config `yaml:"logging"`
+
+	// Persistent state configuration
+	State state.Config `yaml:"state"`
+}
+
+// DeadlinesConfig sets how long a request may run before the client gives up with a 504.
+// A model entry wins over a route entry, which wins over the default. Zero means no deadline.
+type DeadlinesConfig struct {
+	Default time.Duration            `yaml:"default"`
+	Routes  map[string]time.Duration `yaml:"routes"` // e.g. /v1/chat/completions: 10m
+	Models  map[string]time.Duration `yaml:"models"` // e.g. Qwen/Qwen3-32B: 20m
+}
+
+// For returns the deadline for a request to route serving model (model may be empty).
+func (d DeadlinesConfig) For(route, model string) time.Duration {
+	if model != "" {
+		if v, ok := d.Models[model]; ok {
+			return v
+		}
+	}
+	if v, ok := d.Routes[route]; ok {
+		return v
+	}
+	return d.Default
+}
+
+// Max returns the longest configured deadline.
+func (d DeadlinesConfig) Max() time.Duration {
+	longest := d.Default
+	for _, v := range d.Routes {
+		longest = max(longest, v)
+	}
+	for _, v := range d.Models {
+		longest = max(longest, v)
+	}
+	return longest
+}
+
+// AdmissionConfig bounds how many inference requests run at once. Requests over
+// the limit wait in a bounded queue and are rejected with 503 when it is full
+// or their wait exceeds QueueTimeout.
+type AdmissionConfig struct {
+	MaxConcurrent         int            `yaml:"max_concurrent"`           // per node
+	MaxConcurrentPerModel int            `yaml:"max_concurrent_per_model"` // default per model; 0 = node limit only
+	Models                map[string]int `yaml:"models"`                   // per-model overrides
+	MaxQueue              int            `yaml:"max_queue"`
+	QueueTimeout          time.Duration  `yaml:"queue_timeout"`
+}
+
+// ModelLimit returns the concurrency limit for model, or 0 when only the node limit applies.
+func (a AdmissionConfig) ModelLimit(model string) int {
+	if v, ok := a.Models[model]; ok {
+		return v
+	}
+	return a.MaxConcurrentPerModel
 }
 
 // Load loads the configuration from a YAML file
@@ -39,10 +126,26 @@ func Load(path string) (*Config, error) {
 	// Set default values
 	cfg.Server.Port = 8080
 	cfg.Server.Host = "0.0.0.0"
+	cfg.Server.ReadTimeout = 30 * time.Second
+	cfg.Server.Admission.MaxConcurrent = 8
+	cfg.Server.Admission.MaxQueue = 16
+	cfg.Server.Admission.QueueTimeout = 30 * time.Second
+	cfg.Server.Deadlines.Default = 30 * time.Second
+	cfg.Server.Deadlines.Routes = map[string]time.Duration{
+		"/v1/chat/completions": 10 * time.Minute,
+		"/v1/completions":      10 * time.Minute,
+	}
 	cfg.Provider.URL = "http://localhost:80"
 	cfg.Provider.ProviderType = "ollama"
 	cfg.Provider.LLMURL = "http://localhost:11434"
 
+	cfg.GPU.SampleInterval = 5 * time.Second
+	cfg.GPU.UtilizationSmoothing = 0.3
+	cfg.GPU.Protection = gpu.DefaultProtection()
+	cfg.GPU.OwnerPriority = gpu.DefaultOwnerPriority()
+	cfg.Pricing.Advisor = pricing.DefaultAdvisor()
+	cfg.Pricing.Dynamic = pricing.DefaultDynamic()
+
 	// Set default logging configuration
 	homeDir, err := os.UserHomeDir()
 	if err == nil {
@@ -52,6 +155,8 @@ func Load(path string) (*Config, error) {
 	cfg.Logging.MaxSize = 100
 	cfg.Logging.MaxBackups = 5
 	cfg.Logging.MaxAge = 30
+	cfg.State.FlushInterval = state.DefaultFlushInterval
+	cfg.Verification = verify.DefaultConfig()
 
 	// Read configuration file
 	data, err := os.ReadFile(path)
@@ -59,6 +164,8 @@ func Load(path string) (*Config, error) {
 		// If file doesn't exist, use default configuration
 		if os.IsNotExist(err) {
 			fmt.Printf("Configuration file %s not found, using defaults\n", path)
+			cfg.setBackendProcesses()
+			cfg.setStateDir()
 			return cfg, nil
 		}
 		return nil, fmt.Errorf("failed to read configuration file: %w", err)
@@ -68,10 +175,62 @@ func Load(path string) (*Config, error) {
 	if err := yaml.Unmarshal(data, cfg); err != nil {
 		return nil, fmt.Errorf("failed to parse configuration file: %w", err)
 	}
+	if err := cfg.Pricing.Policy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid pricing configuration: %w", err)
+	}
+	if err := cfg.Pricing.Dynamic.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid dynamic pricing configuration: %w", err)
+	}
+	if err := cfg.Verification.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid verification configuration: %w", err)
+	}
+	cfg.setBackendProcesses()
+	cfg.setStateDir()
 
 	return cfg, nil
 }
 
+// setBackendProcesses derives the owner-priority backend processes from the
+// provider type unless they are configured.
+func (c *Config) setBackendProcesses() {
+	if len(c.GPU.OwnerPriority.BackendProcesses) == 0 {
+		c.GPU.OwnerPriority.BackendProcesses = gpu.BackendProcessesFor(c.Provider.ProviderType)
+	}
+}
+
+// setStateDir puts the state file next to the log directory unless configured,
+// e.g. ~/.local/state/inferoute for logs in ~/.local/state/inferoute/log.
+func (c *Config) setStateDir() {
+	if c.State.Dir == "" && c.Logging.LogDir != "" {
+		c.State.Dir = filepath.Dir(c.Logging.LogDir)
+	}
+}
+
+// Locate returns the first configuration file found in the standard locations:
+// ~/.config/inferoute/config.yaml, then config.yaml in the current directory.
+func Locate() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	locations := []string{
+		filepath.Join(homeDir, ".config", "inferoute", "config.yaml"),
+		"config.yaml", // Current directory
+	}
+	for _, location := range locations {
+		if _, err := os.Stat(location); err == nil {
+			return location, nil
+		}
+	}
+
+	msg := "no configuration file found in standard locations:"
+	for _, location := range locations {
+		msg += "\n  - " + location
+	}
+	return "", fmt.Errorf("%s", msg)
+}
+
 // TunnelServiceURL returns the URL the Cloudflare tunnel should target (the proxy).
 // Uses localhost when Server.Host is 0.0.0.0 so cloudflared connects to the proxy on the same machine.
 func (c *Config) TunnelServiceURL() string {
@@ -45,9 +45,10 @@ type Client struct {
 	bearerToken string
 	serviceURL  string
 
-	// Runtime state
+	// Runtime state; token, hostname and saved are guarded by mu
 	token    string
 	hostname string
+	saved    TunnelState // tunnel from the previous run, used if the request fails
 	cmd      *exec.Cmd
 	process  *os.Process
 
@@ -96,8 +97,30 @@ func NewClient(coreURL, bearerToken, serviceURL string) *Client {
 	}
 }
 
-// RequestTunnel requests a new tunnel from the core system
+// RequestTunnel requests a new tunnel from the core system. If the core system
+// cannot be reached and a tunnel was saved from the previous run, that tunnel
+// is reused so the node keeps serving on its old hostname.
 func (c *Client) RequestTunnel(ctx context.Context) error {
+	tunnel, err := c.requestTunnel(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		if c.saved.Token == "" {
+			return err
+		}
+		appLogger.Warn("Reusing the tunnel from the previous run",
+			zap.String("hostname", c.saved.Hostname),
+			zap.Error(err))
+		tunnel = TunnelResponse{Token: c.saved.Token, Hostname: c.saved.Hostname}
+	}
+	c.token = tunnel.Token
+	c.hostname = tunnel.Hostname
+	return nil
+}
+
+// requestTunnel asks the core system for a tunnel token and hostname.
+func (c *Client) requestTunnel(ctx context.Context) (TunnelResponse, error) {
 	url := fmt.Sprintf("%s/api/cloudflare/tunnel/request", c.coreURL)
 
 	reqBody := TunnelRequest{
@@ -106,7 +129,7 @@ func (c *Client) RequestTunnel(ctx context.Context) error {
 
 	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+		return TunnelResponse{}, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	appLogger.Debug("Requesting Cloudflare tunnel",
@@ -115,7 +138,7 @@ func (c *Client) RequestTunnel(ctx context.Context) error {
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(jsonBody))
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return TunnelResponse{}, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -124,28 +147,25 @@ func (c *Client) RequestTunnel(ctx context.Context) error {
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
 		appLogger.Error("Failed to request Cloudflare tunnel", zap.Error(err))
-		return fmt.Errorf("failed to request tunnel: %w", err)
+		return TunnelResponse{}, fmt.Errorf("failed to request tunnel: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		appLogger.Error("Cloudflare tunnel API returned non-OK status", zap.Int("status_code", resp.StatusCode))
-		return fmt.Errorf("tunnel API returned status code: %d", resp.StatusCode)
+		return TunnelResponse{}, fmt.Errorf("tunnel API returned status code: %d", resp.StatusCode)
 	}
 
 	var tunnelResp TunnelResponse
 	if err := json.NewDecoder(resp.Body).Decode(&tunnelResp); err != nil {
 		appLogger.Error("Failed to decode tunnel response", zap.Error(err))
-		return fmt.Errorf("failed to decode tunnel response: %w", err)
+		return TunnelResponse{}, fmt.Errorf("failed to decode tunnel response: %w", err)
 	}
 
-	c.token = tunnelResp.Token
-	c.hostname = tunnelResp.Hostname
-
 	appLogger.Info("Cloudflare tunnel requested successfully",
-		zap.String("hostname", c.hostname))
+		zap.String("hostname", tunnelResp.Hostname))
 
-	return nil
+	return tunnelResp, nil
 }
 
 // StartTunnel starts the cloudflared process with comprehensive supervision
@@ -372,12 +392,15 @@ func (c *Client) handleRestart() {
 	// Clean up old process
 	c.cleanupProcess()
 
-	// Request a fresh token before restarting (in case old token expired)
+	// Request a fresh token before restarting (in case old token expired).
+	// c.mu is held, so the fields are set here rather than by RequestTunnel.
 	appLogger.Info("Requesting fresh token before restart")
-	if err := c.RequestTunnel(context.Background()); err != nil {
+	if tunnel, err := c.requestTunnel(context.Background()); err != nil {
 		appLogger.Error("Failed to get fresh token for restart", zap.Error(err))
 		// Continue with old token as fallback
 	} else {
+		c.token = tunnel.Token
+		c.hostname = tunnel.Hostname
 		appLogger.Info("Got fresh token for restart", zap.String("hostname", c.hostname))
 	}
 
@@ -510,11 +533,20 @@ func (c *Client) RestartTunnel() error {
 
 // GetHostname returns the current tunnel hostname
 func (c *Client) GetHostname() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.hostname
 }
 
 // GetTunnelURL returns the full tunnel URL (with https prefix)
 func (c *Client) GetTunnelURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.tunnelURL()
+}
+
+// tunnelURL is GetTunnelURL for callers holding c.mu.
+func (c *Client) tunnelURL() string {
 	if c.hostname == "" {
 		return ""
 	}
@@ -537,7 +569,7 @@ func (c *Client) GetStatus() map[string]interface{} {
 		"supervision_active": c.running,
 		"process_running":    c.isProcessRunning(),
 		"hostname":           c.hostname,
-		"url":                c.GetTunnelURL(),
+		"url":                c.tunnelURL(),
 		"restart_count":      c.restartCount,
 		"should_restart":     c.shouldRestart,
 	}
@@ -552,3 +584,26 @@ func (c *Client) GetStatus() map[string]interface{} {
 
 	return status
 }
+
+// TunnelState is the tunnel's hostname and token, saved across restarts.
+type TunnelState struct {
+	Hostname string `json:"hostname"`
+	Token    string `json:"token"`
+}
+
+// State returns the current tunnel, or the saved one if none was requested yet.
+func (c *Client) State() TunnelState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.token == "" {
+		return c.saved
+	}
+	return TunnelState{Hostname: c.hostname, Token: c.token}
+}
+
+// RestoreState keeps a saved tunnel to fall back on in RequestTunnel.
+func (c *Client) RestoreState(st TunnelState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.saved = st
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
 )
 
@@ -23,6 +24,8 @@ Flags:
   --offline-catalog path   Load catalog JSON from a local file instead of the network
   --json                   Emit machine-readable JSON
   --show-too-large         Include too_large models in table/JSON model list (default: true)
+  --ollama-models path     Ollama models directory; installed models are sized from their GGUF header
+                           (default: $OLLAMA_MODELS or ~/.ollama/models)
   --help                   Show this help
 `
 
@@ -33,6 +36,7 @@ type Options struct {
 	OfflineCatalog string
 	JSON           bool
 	ShowTooLarge   bool
+	OllamaModels   string // Ollama models dir for local GGUF headers; empty = default
 }
 
 // Run parses args and prints the compatibility report.
@@ -48,6 +52,7 @@ func Run(args []string) error {
 	fs.StringVar(&opts.CatalogURL, "catalog-url", defaultCatalogURL, "Inferoute catalog base URL")
 	fs.StringVar(&opts.OfflineCatalog, "offline-catalog", "", "Path to offline approved-builds JSON")
 	fs.BoolVar(&opts.JSON, "json", false, "Emit JSON output")
+	fs.StringVar(&opts.OllamaModels, "ollama-models", "", "Ollama models directory (default: $OLLAMA_MODELS or ~/.ollama/models)")
 	showTooLarge := fs.Bool("show-too-large", true, "Include too_large models in the model list")
 	fs.Usage = func() {
 		fmt.Fprint(os.Stderr, compatibilityHelp)
@@ -94,7 +99,7 @@ func Execute(opts Options) error {
 		return err
 	}
 
-	results := ScoreModels(hw, catalogEntries)
+	results := ScoreModels(hw, catalogEntries, localGGUF(opts, catalogEntries))
 	report := BuildReport(hw, results, opts.ShowTooLarge)
 
 	if opts.JSON {
@@ -103,6 +108,28 @@ func Execute(opts Options) error {
 	return WriteTable(os.Stdout, report)
 }
 
+// localGGUF reads the GGUF headers of approved Ollama models installed here,
+// so scoring can use their real attention shape. Models not installed are skipped.
+func localGGUF(opts Options, entries []verify.CatalogEntry) map[string]gguf.Metadata {
+	dir := opts.OllamaModels
+	if dir == "" {
+		var err error
+		if dir, err = verify.DefaultOllamaModels(); err != nil {
+			return nil
+		}
+	}
+	local := make(map[string]gguf.Metadata)
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.ServiceType, "ollama") {
+			continue
+		}
+		if meta, err := verify.LocalGGUF(dir, entry.Alias); err == nil {
+			local[entry.Alias] = meta
+		}
+	}
+	return local
+}
+
 func loadEntries(ctx context.Context, opts Options) ([]verify.CatalogEntry, error) {
 	if opts.OfflineCatalog != "" {
 		return LoadOfflineCatalog(opts.OfflineCatalog, opts.ProviderType)
@@ -2,10 +2,10 @@ package compat
 
 import (
 	"fmt"
-	"os/exec"
 	"runtime"
-	"strconv"
 	"strings"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
 )
 
 // MemoryKind describes which memory pool the scorer should use.
@@ -35,7 +35,7 @@ type Hardware struct {
 	Warnings         []string   `json:"warnings,omitempty"`
 }
 
-// Detect probes local hardware for Linux NVIDIA and macOS (Apple Silicon/Intel).
+// Detect probes local hardware for Linux NVIDIA/AMD and macOS (Apple Silicon/Intel).
 // It never hard-fails solely because a GPU is missing; CPU/system-RAM fallbacks are used.
 func Detect() (*Hardware, error) {
 	hw := &Hardware{
@@ -57,9 +57,13 @@ func Detect() (*Hardware, error) {
 			fallbackSystemMemory(hw, "macOS GPU detection failed; using system RAM")
 		}
 	case "linux":
-		if err := detectLinuxNVIDIA(hw); err != nil {
+		probe, err := gpu.DetectProbe()
+		if err == nil {
+			err = detectGPU(hw, probe)
+		}
+		if err != nil {
 			hw.Warnings = append(hw.Warnings, err.Error())
-			fallbackSystemMemory(hw, "NVIDIA GPU not detected; scoring against system RAM (slow/CPU path)")
+			fallbackSystemMemory(hw, "NVIDIA/AMD GPU not detected; scoring against system RAM (slow/CPU path)")
 		}
 	default:
 		fallbackSystemMemory(hw, fmt.Sprintf("unsupported OS %s; using system RAM if available", runtime.GOOS))
@@ -95,16 +99,14 @@ func fallbackSystemMemory(hw *Hardware, warning string) {
 const appleSiliconUsableFraction = 0.65
 
 func detectDarwin(hw *Hardware) error {
-	productName, coreCount, err := parseSystemProfilerGPU()
+	info, err := gpu.NewAppleProbe().Probe()
 	if err != nil {
 		return err
 	}
+	productName := info.ProductName
 	hw.ProductName = productName
-	if coreCount > 0 {
-		hw.ProductName = fmt.Sprintf("%s (%d cores)", productName, coreCount)
-	}
-	hw.DriverVersion = "macOS Native"
-	hw.CUDAVersion = "N/A"
+	hw.DriverVersion = info.DriverVersion
+	hw.CUDAVersion = info.CUDAVersion
 	hw.GPUCount = 1
 
 	isAppleSilicon := runtime.GOARCH == "arm64" ||
@@ -127,156 +129,50 @@ func detectDarwin(hw *Hardware) error {
 	return nil
 }
 
-func parseSystemProfilerGPU() (productName string, coreCount int, err error) {
-	if _, lookErr := exec.LookPath("system_profiler"); lookErr != nil {
-		return "", 0, fmt.Errorf("system_profiler not found: %w", lookErr)
-	}
-	cmd := exec.Command("system_profiler", "SPDisplaysDataType")
-	output, err := cmd.Output()
+// detectGPU fills hw from a discrete-GPU probe (nvidia-smi or rocm-smi).
+func detectGPU(hw *Hardware, probe gpu.Probe) error {
+	info, err := probe.Probe()
 	if err != nil {
-		return "", 0, fmt.Errorf("system_profiler: %w", err)
-	}
-
-	lines := strings.Split(string(output), "\n")
-	for i, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.Contains(line, "Chipset Model:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				productName = strings.TrimSpace(parts[1])
-			}
-		}
-		if strings.Contains(line, "Total Number of Cores:") {
-			parts := strings.SplitN(line, ":", 2)
-			if len(parts) == 2 {
-				if n, atoiErr := strconv.Atoi(strings.TrimSpace(parts[1])); atoiErr == nil {
-					coreCount = n
-				}
-			}
-		}
-		if productName == "" && i > 0 && strings.HasSuffix(strings.TrimSpace(lines[i-1]), ":") {
-			productName = strings.TrimSuffix(strings.TrimSpace(lines[i-1]), ":")
-		}
-	}
-	if productName == "" {
-		productName = "Unknown macOS GPU"
-	}
-	return productName, coreCount, nil
-}
-
-func detectLinuxNVIDIA(hw *Hardware) error {
-	if _, err := exec.LookPath("nvidia-smi"); err != nil {
-		return fmt.Errorf("nvidia-smi not found: %w", err)
-	}
-	cmd := exec.Command("nvidia-smi", "--query-gpu=name,driver_version,memory.total,memory.used,memory.free,uuid", "--format=csv,noheader,nounits")
-	output, err := cmd.Output()
-	if err != nil {
-		return fmt.Errorf("nvidia-smi: %w", err)
-	}
-
-	cudaVersion := queryCUDAVersion()
-
-	type gpuRow struct {
-		name   string
-		driver string
-		total  int64
-		used   int64
-		free   int64
-	}
-	var gpus []gpuRow
-	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-		parts := splitCSV(line)
-		if len(parts) < 5 {
-			continue
-		}
-		totalMiB, _ := strconv.ParseInt(strings.TrimSpace(parts[2]), 10, 64)
-		usedMiB, _ := strconv.ParseInt(strings.TrimSpace(parts[3]), 10, 64)
-		freeMiB, _ := strconv.ParseInt(strings.TrimSpace(parts[4]), 10, 64)
-		gpus = append(gpus, gpuRow{
-			name:   strings.TrimSpace(parts[0]),
-			driver: strings.TrimSpace(parts[1]),
-			total:  totalMiB * 1024 * 1024,
-			used:   usedMiB * 1024 * 1024,
-			free:   freeMiB * 1024 * 1024,
-		})
+		return err
 	}
-	if len(gpus) == 0 {
-		return fmt.Errorf("nvidia-smi returned no GPUs")
+	if len(info.Devices) == 0 {
+		return fmt.Errorf("%s probe returned no GPUs", probe.Vendor())
 	}
 
 	// v1: score against the largest single GPU VRAM.
-	best := gpus[0]
-	for _, g := range gpus[1:] {
-		if g.total > best.total {
-			best = g
+	best := info.Devices[0]
+	for _, d := range info.Devices[1:] {
+		if d.MemoryTotal > best.MemoryTotal {
+			best = d
 		}
 	}
+	const mib = 1024 * 1024
 
-	hw.ProductName = best.name
-	hw.DriverVersion = best.driver
-	hw.CUDAVersion = cudaVersion
-	hw.GPUCount = len(gpus)
+	hw.ProductName = best.ProductName
+	hw.DriverVersion = info.DriverVersion
+	if info.CUDAVersion != "N/A" {
+		hw.CUDAVersion = info.CUDAVersion
+	}
+	hw.GPUCount = len(info.Devices)
 	hw.MemoryKind = MemoryVRAM
 	hw.UnifiedMemory = false
-	hw.MemoryTotalBytes = best.total
-	hw.MemoryUsedBytes = best.used
-	hw.MemoryFreeBytes = best.free
-	hw.UsableBytes = best.total
+	hw.MemoryTotalBytes = best.MemoryTotal * mib
+	hw.MemoryUsedBytes = best.MemoryUsed * mib
+	hw.MemoryFreeBytes = best.MemoryFree * mib
+	hw.UsableBytes = hw.MemoryTotalBytes
 
-	if best.total > 0 && float64(best.free)/float64(best.total) < 0.50 {
+	if best.MemoryTotal > 0 && float64(best.MemoryFree)/float64(best.MemoryTotal) < 0.50 {
 		hw.Warnings = append(hw.Warnings,
 			fmt.Sprintf("free VRAM (%.1f GiB) is much lower than total (%.1f GiB); models may not load until memory is freed",
-				bytesToGiB(best.free), bytesToGiB(best.total)))
+				bytesToGiB(hw.MemoryFreeBytes), bytesToGiB(hw.MemoryTotalBytes)))
 	}
-	if len(gpus) > 1 {
+	if len(info.Devices) > 1 {
 		hw.Warnings = append(hw.Warnings,
-			fmt.Sprintf("%d GPUs detected; scoring against largest single GPU (%.1f GiB)", len(gpus), bytesToGiB(best.total)))
+			fmt.Sprintf("%d GPUs detected; scoring against largest single GPU (%.1f GiB)", len(info.Devices), bytesToGiB(hw.MemoryTotalBytes)))
 	}
 	return nil
 }
 
-func queryCUDAVersion() string {
-	cmd := exec.Command("nvidia-smi")
-	out, err := cmd.Output()
-	if err != nil {
-		return ""
-	}
-	for _, line := range strings.Split(string(out), "\n") {
-		if strings.Contains(line, "CUDA Version:") {
-			idx := strings.Index(line, "CUDA Version:")
-			rest := strings.TrimSpace(line[idx+len("CUDA Version:"):])
-			fields := strings.Fields(rest)
-			if len(fields) > 0 {
-				return fields[0]
-			}
-		}
-	}
-	return ""
-}
-
-func splitCSV(line string) []string {
-	var parts []string
-	var cur strings.Builder
-	inQuotes := false
-	for _, r := range line {
-		switch {
-		case r == '"':
-			inQuotes = !inQuotes
-		case r == ',' && !inQuotes:
-			parts = append(parts, cur.String())
-			cur.Reset()
-		default:
-			cur.WriteRune(r)
-		}
-	}
-	parts = append(parts, cur.String())
-	return parts
-}
-
 func bytesToGiB(b int64) float64 {
 	return float64(b) / (1024 * 1024 * 1024)
 }
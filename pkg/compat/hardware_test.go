@@ -3,15 +3,46 @@ package compat
 import (
 	"strings"
 	"testing"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
 )
 
-func TestParseNvidiaCSVQuoted(t *testing.T) {
-	parts := splitCSV(`"NVIDIA GeForce RTX 4090", 550.54.15, 24564, 1024, 23540`)
-	if len(parts) < 5 {
-		t.Fatalf("parts=%v", parts)
+func TestDetectGPUFromAMDFixture(t *testing.T) {
+	probe, err := gpu.NewFixtureProbe(gpu.VendorAMD, "../gpu/testdata/rocm-smi-2gpu.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hw := &Hardware{}
+	if err := detectGPU(hw, probe); err != nil {
+		t.Fatal(err)
+	}
+	// Largest single GPU is the MI300X (card1).
+	if hw.ProductName != "AMD Instinct MI300X" || hw.GPUCount != 2 || hw.MemoryKind != MemoryVRAM {
+		t.Fatalf("hw = %+v", hw)
+	}
+	if hw.UsableBytes != hw.MemoryTotalBytes || hw.MemoryTotalBytes != 196288*1024*1024 {
+		t.Fatalf("usable=%d total=%d", hw.UsableBytes, hw.MemoryTotalBytes)
+	}
+	if hw.CUDAVersion != "" || hw.DriverVersion != "6.7.0" {
+		t.Fatalf("cuda=%q driver=%q", hw.CUDAVersion, hw.DriverVersion)
+	}
+}
+
+func TestDetectGPUFromNVIDIAFixture(t *testing.T) {
+	probe, err := gpu.NewFixtureProbe(gpu.VendorNVIDIA, "../gpu/testdata/nvidia-smi-2gpu.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	hw := &Hardware{}
+	if err := detectGPU(hw, probe); err != nil {
+		t.Fatal(err)
+	}
+	if hw.ProductName != "NVIDIA A100-SXM4-80GB" || hw.CUDAVersion != "12.4" || hw.MemoryTotalBytes != 81920*1024*1024 {
+		t.Fatalf("hw = %+v", hw)
 	}
-	if strings.TrimSpace(parts[0]) != "NVIDIA GeForce RTX 4090" {
-		t.Fatalf("got %q", parts[0])
+	// First card has 11920 of 81920 MiB free, so the low-free-VRAM warning fires.
+	if len(hw.Warnings) != 2 {
+		t.Fatalf("warnings = %v, want low free VRAM and multi-GPU", hw.Warnings)
 	}
 }
 
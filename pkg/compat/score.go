@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
 )
 
@@ -30,19 +31,39 @@ type ModelResult struct {
 	Reason           string    `json:"reason"`
 	HFRepo           *string   `json:"hf_repo,omitempty"`
 	HFRef            *string   `json:"hf_ref,omitempty"`
+	GGUF             *gguf.Metadata `json:"gguf,omitempty"` // header of the installed Ollama model, if any
 }
 
+// ollamaContextTokens is Ollama's default num_ctx, the context the KV cache is
+// sized for when the model's GGUF header is available.
+const ollamaContextTokens = 4096
+
+// ollamaRuntimeOverhead covers compute buffers on top of weights and KV cache.
+const ollamaRuntimeOverhead = 1.10
+
 // ScoreModels scores approved catalog entries against detected hardware.
-func ScoreModels(hw *Hardware, entries []verify.CatalogEntry) []ModelResult {
+// local holds GGUF headers of installed Ollama models by alias; it may be nil.
+func ScoreModels(hw *Hardware, entries []verify.CatalogEntry, local map[string]gguf.Metadata) []ModelResult {
 	out := make([]ModelResult, 0, len(entries))
 	for _, entry := range entries {
-		out = append(out, ScoreModel(hw, entry))
+		var meta *gguf.Metadata
+		if m, ok := local[entry.Alias]; ok {
+			meta = &m
+		}
+		out = append(out, ScoreLocalModel(hw, entry, meta))
 	}
 	return out
 }
 
 // ScoreModel scores a single approved catalog entry.
 func ScoreModel(hw *Hardware, entry verify.CatalogEntry) ModelResult {
+	return ScoreLocalModel(hw, entry, nil)
+}
+
+// ScoreLocalModel scores an entry using the installed model's GGUF header when
+// meta is set: memory is then weights plus a KV cache sized from the model's
+// attention shape, instead of a flat overhead factor.
+func ScoreLocalModel(hw *Hardware, entry verify.CatalogEntry, meta *gguf.Metadata) ModelResult {
 	res := ModelResult{
 		Alias:         entry.Alias,
 		DisplayName:   entry.DisplayName,
@@ -51,6 +72,7 @@ func ScoreModel(hw *Hardware, entry verify.CatalogEntry) ModelResult {
 		UsableBytes:    0,
 		HFRepo:        entry.HFRepo,
 		HFRef:         entry.HFRef,
+		GGUF:          meta,
 	}
 	if res.DisplayName == "" {
 		res.DisplayName = entry.Alias
@@ -71,11 +93,23 @@ func ScoreModel(hw *Hardware, entry verify.CatalogEntry) ModelResult {
 	}
 
 	required := requiredMemoryBytes(entry.MinSizeBytes, entry.ServiceType)
+	var kvNote string
+	if meta != nil {
+		contextTokens := uint64(ollamaContextTokens)
+		if meta.ContextLength > 0 {
+			contextTokens = min(contextTokens, meta.ContextLength)
+		}
+		if kv := meta.KVCacheBytes(contextTokens); kv > 0 {
+			required = int64(float64(entry.MinSizeBytes)*ollamaRuntimeOverhead) + kv
+			kvNote = fmt.Sprintf("; %s %s, %s params, KV cache for %d tokens ~%s",
+				meta.Architecture, meta.Quantization, formatParams(meta.ParameterCount), contextTokens, formatBytes(kv))
+		}
+	}
 	res.RequiredBytes = required
 
 	ratio := float64(required) / float64(hw.UsableBytes)
 	baseReason := fmt.Sprintf("needs ~%s; usable %s (%s)",
-		formatBytes(required), formatBytes(hw.UsableBytes), hw.MemoryKind)
+		formatBytes(required), formatBytes(hw.UsableBytes), hw.MemoryKind) + kvNote
 
 	switch {
 	case ratio < 0.50:
@@ -150,6 +184,18 @@ func formatBytes(b int64) string {
 	}
 }
 
+// formatParams renders a parameter count such as 8.03B or 494M.
+func formatParams(n uint64) string {
+	switch {
+	case n >= 1e9:
+		return fmt.Sprintf("%.2fB", float64(n)/1e9)
+	case n >= 1e6:
+		return fmt.Sprintf("%.0fM", float64(n)/1e6)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+}
+
 // StatusRank orders statuses for display (best first).
 func StatusRank(s FitStatus) int {
 	switch s {
@@ -6,6 +6,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
 )
 
@@ -94,6 +95,30 @@ func TestVLLMUsesHigherOverhead(t *testing.T) {
 	}
 }
 
+func TestLocalGGUFSizesKVCache(t *testing.T) {
+	hw := &Hardware{MemoryKind: MemoryVRAM, UsableBytes: 24 << 30}
+	entry := verify.CatalogEntry{Alias: "gguf/llama3:8b", ServiceType: "ollama", MinSizeBytes: 4 << 30}
+	// Llama 3 8B: 32 layers, 8 KV heads of dim 128; 128 KiB of KV cache per token.
+	meta := gguf.Metadata{
+		Architecture: "llama", Quantization: "Q4_K_M", ParameterCount: 8_030_000_000, ContextLength: 8192,
+		BlockCount: 32, EmbeddingLength: 4096, HeadCount: 32, HeadCountKV: 8,
+	}
+	results := ScoreModels(hw, []verify.CatalogEntry{entry}, map[string]gguf.Metadata{entry.Alias: meta})
+	got := results[0]
+	want := int64(float64(entry.MinSizeBytes)*ollamaRuntimeOverhead) + 4096*128<<10
+	if got.RequiredBytes != want || got.GGUF == nil {
+		t.Fatalf("required=%d, want %d (gguf %v)", got.RequiredBytes, want, got.GGUF)
+	}
+	if !strings.Contains(got.Reason, "Q4_K_M, 8.03B params, KV cache for 4096 tokens ~512.0 MiB") {
+		t.Fatalf("reason: %s", got.Reason)
+	}
+
+	// Without a header the flat Ollama overhead applies.
+	if plain := ScoreModels(hw, []verify.CatalogEntry{entry}, nil)[0]; plain.RequiredBytes != requiredMemoryBytes(entry.MinSizeBytes, "ollama") {
+		t.Fatalf("required without header = %d", plain.RequiredBytes)
+	}
+}
+
 func TestReportJSONStableShape(t *testing.T) {
 	hw := &Hardware{
 		OS: "darwin", Arch: "arm64", ProductName: "Apple M2",
@@ -133,17 +158,6 @@ func TestReportJSONStableShape(t *testing.T) {
 	}
 }
 
-func TestParseSystemProfilerGPU(t *testing.T) {
-	// Exercise splitCSV used by Linux path.
-	parts := splitCSV(`NVIDIA GeForce RTX 4090, 550.54.15, 24564, 1024, 23540, GPU-uuid`)
-	if len(parts) < 5 {
-		t.Fatalf("parts=%v", parts)
-	}
-	if parts[0] != "NVIDIA GeForce RTX 4090" {
-		t.Fatalf("name=%q", parts[0])
-	}
-}
-
 func TestLoadOfflineCatalogFilters(t *testing.T) {
 	dir := t.TempDir()
 	path := dir + "/catalog.json"
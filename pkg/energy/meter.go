@@ -0,0 +1,231 @@
+// Package energy attributes GPU energy to the inference requests that used it.
+package energy
+
+import (
+	"sort"
+	"sync"
+)
+
+const joulesPerKWh = 3.6e6
+
+// Config sets the electricity rate used to estimate energy cost.
+type Config struct {
+	PricePerKWh float64 `yaml:"price_per_kwh"` // USD; 0 hides cost estimates
+}
+
+// PriceLookup returns a model's USD price per million input and output tokens.
+type PriceLookup func(model string) (inputPer1M, outputPer1M float64, ok bool)
+
+// Usage is the token count reported for a finished request.
+type Usage struct {
+	PromptTokens     int64
+	CompletionTokens int64
+}
+
+// Request is one in-flight request being metered.
+type Request struct {
+	Model  string
+	Joules float64
+}
+
+// ModelStats totals the energy, tokens and estimated money for one model.
+type ModelStats struct {
+	Model             string  `json:"model"`
+	Requests          int     `json:"requests"`
+	Joules            float64 `json:"joules"`
+	PromptTokens      int64   `json:"prompt_tokens"`
+	CompletionTokens  int64   `json:"completion_tokens"`
+	EstimatedCost     float64 `json:"estimated_cost"`
+	EstimatedEarnings float64 `json:"estimated_earnings"`
+}
+
+// Stats is a snapshot of the meter. Joules counts energy attributed to
+// requests; IdleJoules is what the GPUs drew while nothing was running.
+type Stats struct {
+	Requests          int          `json:"requests"`
+	Joules            float64      `json:"joules"`
+	IdleJoules        float64      `json:"idle_joules"`
+	KWh               float64      `json:"kwh"`
+	PricePerKWh       float64      `json:"price_per_kwh"`
+	EstimatedCost     float64      `json:"estimated_cost"`
+	EstimatedEarnings float64      `json:"estimated_earnings"`
+	Models            []ModelStats `json:"models"`
+}
+
+// Meter splits the GPU energy counter between in-flight requests. Energy drawn
+// while several requests run is shared evenly between them, so concurrent
+// requests are not each charged for the whole GPU. A nil *Meter is a no-op.
+type Meter struct {
+	source      func() float64 // cumulative joules, e.g. gpu.Monitor.EnergyJoules
+	pricePerKWh float64
+	prices      PriceLookup
+
+	mu     sync.Mutex
+	last   float64
+	active map[*Request]struct{}
+	idle   float64
+	models map[string]*ModelStats
+}
+
+// NewMeter creates a meter reading a monotonically increasing joule counter.
+// A nil source meters tokens only and reports zero energy.
+func NewMeter(source func() float64, cfg Config) *Meter {
+	m := &Meter{
+		source:      source,
+		pricePerKWh: cfg.PricePerKWh,
+		active:      make(map[*Request]struct{}),
+		models:      make(map[string]*ModelStats),
+	}
+	m.last = m.read()
+	return m
+}
+
+// SetPriceLookup sets how token counts are turned into estimated earnings.
+func (m *Meter) SetPriceLookup(prices PriceLookup) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.prices = prices
+}
+
+// Begin starts metering a request for model.
+func (m *Meter) Begin(model string) *Request {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+	r := &Request{Model: model}
+	m.active[r] = struct{}{}
+	return r
+}
+
+// End stops metering r, adds it to its model's totals and returns the joules
+// attributed to it.
+func (m *Meter) End(r *Request, usage Usage) float64 {
+	if m == nil || r == nil {
+		return 0
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+	delete(m.active, r)
+
+	stats, ok := m.models[r.Model]
+	if !ok {
+		stats = &ModelStats{Model: r.Model}
+		m.models[r.Model] = stats
+	}
+	stats.Requests++
+	stats.Joules += r.Joules
+	stats.PromptTokens += usage.PromptTokens
+	stats.CompletionTokens += usage.CompletionTokens
+	if m.prices != nil {
+		if in, out, ok := m.prices(r.Model); ok {
+			stats.EstimatedEarnings += (float64(usage.PromptTokens)*in + float64(usage.CompletionTokens)*out) / 1e6
+		}
+	}
+	return r.Joules
+}
+
+// Stats returns the totals so far, with models sorted by name.
+func (m *Meter) Stats() Stats {
+	if m == nil {
+		return Stats{Models: []ModelStats{}}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+
+	out := Stats{IdleJoules: m.idle, PricePerKWh: m.pricePerKWh, Models: make([]ModelStats, 0, len(m.models))}
+	for _, s := range m.models {
+		model := *s
+		model.EstimatedCost = m.cost(model.Joules)
+		out.Requests += model.Requests
+		out.Joules += model.Joules
+		out.EstimatedEarnings += model.EstimatedEarnings
+		out.Models = append(out.Models, model)
+	}
+	sort.Slice(out.Models, func(i, j int) bool { return out.Models[i].Model < out.Models[j].Model })
+	out.KWh = out.Joules / joulesPerKWh
+	out.EstimatedCost = m.cost(out.Joules)
+	return out
+}
+
+// advance shares the energy drawn since the last call between the active
+// requests. Callers hold m.mu.
+func (m *Meter) advance() {
+	now := m.read()
+	delta := now - m.last
+	m.last = now
+	if delta <= 0 {
+		return
+	}
+	if len(m.active) == 0 {
+		m.idle += delta
+		return
+	}
+	share := delta / float64(len(m.active))
+	for r := range m.active {
+		r.Joules += share
+	}
+}
+
+func (m *Meter) read() float64 {
+	if m.source == nil {
+		return 0
+	}
+	return m.source()
+}
+
+func (m *Meter) cost(joules float64) float64 {
+	return joules / joulesPerKWh * m.pricePerKWh
+}
+
+// Totals are the meter's accumulated counters, saved across restarts.
+type Totals struct {
+	IdleJoules float64      `json:"idle_joules"`
+	Models     []ModelStats `json:"models"`
+}
+
+// Totals returns the accumulated counters for saving.
+func (m *Meter) Totals() Totals {
+	if m == nil {
+		return Totals{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.advance()
+
+	out := Totals{IdleJoules: m.idle, Models: make([]ModelStats, 0, len(m.models))}
+	for _, s := range m.models {
+		out.Models = append(out.Models, *s)
+	}
+	sort.Slice(out.Models, func(i, j int) bool { return out.Models[i].Model < out.Models[j].Model })
+	return out
+}
+
+// RestoreTotals adds saved counters to the meter's.
+func (m *Meter) RestoreTotals(t Totals) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.idle += t.IdleJoules
+	for _, saved := range t.Models {
+		stats, ok := m.models[saved.Model]
+		if !ok {
+			stats = &ModelStats{Model: saved.Model}
+			m.models[saved.Model] = stats
+		}
+		stats.Requests += saved.Requests
+		stats.Joules += saved.Joules
+		stats.PromptTokens += saved.PromptTokens
+		stats.CompletionTokens += saved.CompletionTokens
+		stats.EstimatedEarnings += saved.EstimatedEarnings
+	}
+}
@@ -0,0 +1,76 @@
+package energy
+
+import (
+	"math"
+	"testing"
+)
+
+// counter is a fake cumulative joule source advanced by the test.
+type counter struct{ joules float64 }
+
+func (c *counter) read() float64 { return c.joules }
+
+func TestMeterSplitsEnergyBetweenConcurrentRequests(t *testing.T) {
+	c := &counter{}
+	m := NewMeter(c.read, Config{PricePerKWh: 0.36})
+	m.SetPriceLookup(func(model string) (float64, float64, bool) {
+		return 1, 2, model == "llama3"
+	})
+
+	c.joules += 100 // idle before any request
+	a := m.Begin("llama3")
+	c.joules += 300 // a alone
+	b := m.Begin("mistral")
+	c.joules += 600 // a and b share
+	if got := m.End(a, Usage{PromptTokens: 1000, CompletionTokens: 500}); got != 600 {
+		t.Fatalf("request a = %v J, want 600", got)
+	}
+	c.joules += 200 // b alone
+	if got := m.End(b, Usage{PromptTokens: 10, CompletionTokens: 10}); got != 500 {
+		t.Fatalf("request b = %v J, want 500", got)
+	}
+
+	stats := m.Stats()
+	if stats.Requests != 2 || stats.Joules != 1100 || stats.IdleJoules != 100 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	// 1100 J at $0.36/kWh = 1100/3.6e6*0.36
+	if math.Abs(stats.EstimatedCost-0.00011) > 1e-12 {
+		t.Fatalf("cost = %v, want 0.00011", stats.EstimatedCost)
+	}
+	if len(stats.Models) != 2 || stats.Models[0].Model != "llama3" {
+		t.Fatalf("models = %+v", stats.Models)
+	}
+	// 1000 input at $1/M + 500 output at $2/M; mistral has no price.
+	if stats.Models[0].EstimatedEarnings != 0.002 || stats.Models[1].EstimatedEarnings != 0 {
+		t.Fatalf("earnings = %v / %v", stats.Models[0].EstimatedEarnings, stats.Models[1].EstimatedEarnings)
+	}
+}
+
+func TestNilMeterIsNoOp(t *testing.T) {
+	var m *Meter
+	r := m.Begin("llama3")
+	if got := m.End(r, Usage{}); got != 0 {
+		t.Fatalf("End = %v, want 0", got)
+	}
+	if stats := m.Stats(); stats.Requests != 0 || stats.Models == nil {
+		t.Fatalf("stats = %+v", stats)
+	}
+}
+
+func TestMeterRestoreTotals(t *testing.T) {
+	c := &counter{}
+	before := NewMeter(c.read, Config{})
+	r := before.Begin("llama3")
+	c.joules += 50
+	before.End(r, Usage{PromptTokens: 10, CompletionTokens: 5})
+
+	after := NewMeter(c.read, Config{})
+	after.RestoreTotals(before.Totals())
+	after.End(after.Begin("llama3"), Usage{PromptTokens: 1, CompletionTokens: 1})
+
+	stats := after.Stats()
+	if stats.Requests != 2 || stats.Joules != 50 || stats.Models[0].PromptTokens != 11 {
+		t.Fatalf("stats after restore = %+v", stats)
+	}
+}
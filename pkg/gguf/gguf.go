@@ -0,0 +1,338 @@
+// Package gguf reads model facts from the header of a GGUF file, the format
+// Ollama and llama.cpp store weights in. Only the header, metadata and tensor
+// index are read; tensor data is never touched.
+package gguf
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+const magic = 0x46554747 // "GGUF" little-endian
+
+// Sanity limits so a corrupt or hostile header cannot make us allocate or loop
+// without bound.
+const (
+	maxKeyLen      = 1 << 16
+	maxStringLen   = 1 << 20 // longer values (chat templates, merges) are skipped
+	maxArrayKeep   = 1 << 12 // longer arrays (token lists) are skipped
+	maxKVCount     = 1 << 20
+	maxTensorCount = 1 << 24
+	maxDims        = 4 // GGML_MAX_DIMS
+)
+
+// ErrNotGGUF is returned for files that do not start with the GGUF magic.
+var ErrNotGGUF = errors.New("not a GGUF file")
+
+// Metadata is what a GGUF header says about its model.
+type Metadata struct {
+	Version         uint32 `json:"version"`
+	Architecture    string `json:"architecture"` // general.architecture, e.g. llama, qwen2
+	Name            string `json:"name,omitempty"`
+	ParameterCount  uint64 `json:"parameter_count"` // sum of tensor elements
+	FileType        int64  `json:"file_type"`       // general.file_type; -1 if absent
+	Quantization    string `json:"quantization"`    // e.g. Q4_K_M
+	ContextLength   uint64 `json:"context_length,omitempty"`
+	TensorCount     uint64 `json:"tensor_count"`
+	BlockCount      uint64 `json:"block_count,omitempty"`
+	EmbeddingLength uint64 `json:"embedding_length,omitempty"`
+	HeadCount       uint64 `json:"head_count,omitempty"`
+	HeadCountKV     uint64 `json:"head_count_kv,omitempty"`
+}
+
+// KVCacheBytes estimates an f16 KV cache for contextTokens tokens, or 0 when
+// the header lacks the attention shape.
+func (m Metadata) KVCacheBytes(contextTokens uint64) int64 {
+	if m.BlockCount == 0 || m.EmbeddingLength == 0 || m.HeadCount == 0 {
+		return 0
+	}
+	kvHeads := m.HeadCountKV
+	if kvHeads == 0 {
+		kvHeads = m.HeadCount
+	}
+	headDim := m.EmbeddingLength / m.HeadCount
+	// K and V, 2 bytes each, per layer, KV head and head dimension.
+	return int64(2 * 2 * m.BlockCount * kvHeads * headDim * contextTokens)
+}
+
+// ReadFile reads the GGUF header of the file at path.
+func ReadFile(path string) (Metadata, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Metadata{}, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read parses a GGUF header from r (version 2 or 3).
+func Read(r io.Reader) (Metadata, error) {
+	d := &decoder{r: bufio.NewReaderSize(r, 1<<16)}
+	m := Metadata{FileType: -1}
+
+	if d.u32() != magic {
+		if d.err != nil {
+			return m, d.fail("magic")
+		}
+		return m, ErrNotGGUF
+	}
+	m.Version = d.u32()
+	if d.err == nil && m.Version != 2 && m.Version != 3 {
+		return m, fmt.Errorf("unsupported GGUF version %d", m.Version)
+	}
+	m.TensorCount = d.u64()
+	kvCount := d.u64()
+	if d.err != nil {
+		return m, d.fail("header")
+	}
+	if m.TensorCount > maxTensorCount || kvCount > maxKVCount {
+		return m, fmt.Errorf("implausible GGUF header: %d tensors, %d metadata keys", m.TensorCount, kvCount)
+	}
+
+	kv := make(map[string]any, 32)
+	for i := uint64(0); i < kvCount; i++ {
+		key := d.str(maxKeyLen)
+		v := d.value(d.u32())
+		if d.err != nil {
+			return m, d.fail("metadata")
+		}
+		if key != "" && v != nil {
+			kv[key] = v
+		}
+	}
+
+	m.Architecture, _ = kv["general.architecture"].(string)
+	m.Name, _ = kv["general.name"].(string)
+	if ft, ok := asUint(kv["general.file_type"]); ok {
+		m.FileType = int64(ft)
+	}
+	arch := m.Architecture
+	m.ContextLength, _ = asUint(kv[arch+".context_length"])
+	m.BlockCount, _ = asUint(kv[arch+".block_count"])
+	m.EmbeddingLength, _ = asUint(kv[arch+".embedding_length"])
+	m.HeadCount, _ = asUint(kv[arch+".attention.head_count"])
+	m.HeadCountKV, _ = asUint(kv[arch+".attention.head_count_kv"])
+
+	// Tensor index: element counts give the parameter count, and the type
+	// holding the most elements stands in when general.file_type is absent.
+	byType := make(map[uint32]uint64)
+	for i := uint64(0); i < m.TensorCount; i++ {
+		d.str(maxKeyLen)
+		dims := d.u32()
+		if d.err == nil && (dims == 0 || dims > maxDims) {
+			return m, fmt.Errorf("tensor %d: %d dimensions", i, dims)
+		}
+		elements := uint64(1)
+		for j := uint32(0); j < dims && d.err == nil; j++ {
+			n := d.u64()
+			if n != 0 && elements > math.MaxUint64/n {
+				return m, fmt.Errorf("tensor %d: element count overflows", i)
+			}
+			elements *= n
+		}
+		typ := d.u32()
+		d.u64() // data offset
+		if d.err != nil {
+			return m, d.fail("tensor index")
+		}
+		m.ParameterCount += elements
+		byType[typ] += elements
+	}
+
+	m.Quantization = fileTypeName(m.FileType)
+	if m.Quantization == "" {
+		m.Quantization = tensorTypeName(dominantType(byType))
+	}
+	return m, nil
+}
+
+func dominantType(byType map[uint32]uint64) uint32 {
+	types := make([]uint32, 0, len(byType))
+	for t := range byType {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	var best uint32
+	var most uint64
+	for _, t := range types {
+		if byType[t] > most {
+			best, most = t, byType[t]
+		}
+	}
+	return best
+}
+
+// Metadata value types.
+const (
+	typeUint8 uint32 = iota
+	typeInt8
+	typeUint16
+	typeInt16
+	typeUint32
+	typeInt32
+	typeFloat32
+	typeBool
+	typeString
+	typeArray
+	typeUint64
+	typeInt64
+	typeFloat64
+)
+
+// decoder reads little-endian GGUF values, remembering the first error.
+type decoder struct {
+	r   *bufio.Reader
+	buf [8]byte
+	err error
+}
+
+func (d *decoder) fail(section string) error {
+	if errors.Is(d.err, io.EOF) || errors.Is(d.err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("GGUF %s: truncated", section)
+	}
+	return fmt.Errorf("GGUF %s: %w", section, d.err)
+}
+
+func (d *decoder) read(n int) []byte {
+	if d.err != nil {
+		return d.buf[:n]
+	}
+	_, d.err = io.ReadFull(d.r, d.buf[:n])
+	return d.buf[:n]
+}
+
+func (d *decoder) skip(n uint64) {
+	for n > 0 && d.err == nil {
+		chunk := int(min(n, 1<<30))
+		_, d.err = d.r.Discard(chunk)
+		n -= uint64(chunk)
+	}
+}
+
+func (d *decoder) u32() uint32 { return binary.LittleEndian.Uint32(d.read(4)) }
+func (d *decoder) u64() uint64 { return binary.LittleEndian.Uint64(d.read(8)) }
+
+// str reads a string, or skips it and returns "" if it is longer than limit.
+func (d *decoder) str(limit uint64) string {
+	n := d.u64()
+	if d.err != nil {
+		return ""
+	}
+	if n > limit {
+		d.skip(n)
+		return ""
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(d.r, b); err != nil {
+		d.err = err
+		return ""
+	}
+	return string(b)
+}
+
+// value reads one metadata value. Long strings and arrays are skipped and
+// come back nil.
+func (d *decoder) value(typ uint32) any {
+	switch typ {
+	case typeUint8:
+		return uint64(d.read(1)[0])
+	case typeInt8:
+		return int64(int8(d.read(1)[0]))
+	case typeUint16:
+		return uint64(binary.LittleEndian.Uint16(d.read(2)))
+	case typeInt16:
+		return int64(int16(binary.LittleEndian.Uint16(d.read(2))))
+	case typeUint32:
+		return uint64(d.u32())
+	case typeInt32:
+		return int64(int32(d.u32()))
+	case typeFloat32:
+		return float64(math.Float32frombits(d.u32()))
+	case typeBool:
+		return d.read(1)[0] != 0
+	case typeString:
+		if s := d.str(maxStringLen); s != "" {
+			return s
+		}
+		return nil
+	case typeUint64:
+		return d.u64()
+	case typeInt64:
+		return int64(d.u64())
+	case typeFloat64:
+		return math.Float64frombits(d.u64())
+	case typeArray:
+		elem, n := d.u32(), d.u64()
+		if d.err != nil {
+			return nil
+		}
+		if elem == typeArray {
+			d.err = fmt.Errorf("nested arrays are not supported")
+			return nil
+		}
+		if size := fixedSize(elem); size > 0 && n > maxArrayKeep {
+			if n > math.MaxUint64/size {
+				d.err = fmt.Errorf("array of %d elements is too large", n)
+				return nil
+			}
+			d.skip(n * size)
+			return nil
+		}
+		var out []any
+		for i := uint64(0); i < n && d.err == nil; i++ {
+			v := d.value(elem)
+			if n <= maxArrayKeep {
+				out = append(out, v)
+			}
+		}
+		return out
+	default:
+		if d.err == nil {
+			d.err = fmt.Errorf("unknown metadata type %d", typ)
+		}
+		return nil
+	}
+}
+
+func fixedSize(typ uint32) uint64 {
+	switch typ {
+	case typeUint8, typeInt8, typeBool:
+		return 1
+	case typeUint16, typeInt16:
+		return 2
+	case typeUint32, typeInt32, typeFloat32:
+		return 4
+	case typeUint64, typeInt64, typeFloat64:
+		return 8
+	}
+	return 0
+}
+
+// asUint reads an integer value. Per-layer arrays (some architectures give
+// head_count_kv per block) yield their largest element.
+func asUint(v any) (uint64, bool) {
+	switch x := v.(type) {
+	case uint64:
+		return x, true
+	case int64:
+		if x >= 0 {
+			return uint64(x), true
+		}
+	case []any:
+		var best uint64
+		found := false
+		for _, e := range x {
+			if n, ok := asUint(e); ok && n >= best {
+				best, found = n, true
+			}
+		}
+		return best, found
+	}
+	return 0, false
+}
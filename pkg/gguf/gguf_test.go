@@ -0,0 +1,116 @@
+package gguf
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// writer builds GGUF headers for tests.
+type writer struct{ bytes.Buffer }
+
+func (w *writer) u32(v uint32) { binary.Write(&w.Buffer, binary.LittleEndian, v) }
+func (w *writer) u64(v uint64) { binary.Write(&w.Buffer, binary.LittleEndian, v) }
+func (w *writer) str(s string) { w.u64(uint64(len(s))); w.WriteString(s) }
+
+func (w *writer) kvString(key, v string) { w.str(key); w.u32(typeString); w.str(v) }
+func (w *writer) kvUint32(key string, v uint32) {
+	w.str(key)
+	w.u32(typeUint32)
+	w.u32(v)
+}
+
+func (w *writer) tensor(name string, typ uint32, dims ...uint64) {
+	w.str(name)
+	w.u32(uint32(len(dims)))
+	for _, d := range dims {
+		w.u64(d)
+	}
+	w.u32(typ)
+	w.u64(0)
+}
+
+func llamaHeader(withFileType bool) []byte {
+	var w writer
+	w.u32(magic)
+	w.u32(3)
+	w.u64(3) // tensors
+	kvs := uint64(8)
+	if withFileType {
+		kvs++
+	}
+	w.u64(kvs)
+	w.kvString("general.architecture", "llama")
+	w.kvString("general.name", "Tiny Llama")
+	if withFileType {
+		w.kvUint32("general.file_type", 15)
+	}
+	w.kvUint32("llama.context_length", 8192)
+	w.kvUint32("llama.block_count", 2)
+	w.kvUint32("llama.embedding_length", 64)
+	w.kvUint32("llama.attention.head_count", 8)
+	w.kvUint32("llama.attention.head_count_kv", 2)
+	// A token list long enough to be skipped rather than kept.
+	w.str("tokenizer.ggml.tokens")
+	w.u32(typeArray)
+	w.u32(typeString)
+	w.u64(maxArrayKeep + 1)
+	for i := 0; i < maxArrayKeep+1; i++ {
+		w.str("tok")
+	}
+	w.tensor("token_embd.weight", 12, 64, 100) // Q4_K
+	w.tensor("blk.0.attn_q.weight", 12, 64, 64)
+	w.tensor("output_norm.weight", 0, 64) // F32
+	return w.Bytes()
+}
+
+func TestRead(t *testing.T) {
+	m, err := Read(bytes.NewReader(llamaHeader(true)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Metadata{
+		Version: 3, Architecture: "llama", Name: "Tiny Llama",
+		ParameterCount: 64*100 + 64*64 + 64, FileType: 15, Quantization: "Q4_K_M",
+		ContextLength: 8192, TensorCount: 3,
+		BlockCount: 2, EmbeddingLength: 64, HeadCount: 8, HeadCountKV: 2,
+	}
+	if m != want {
+		t.Fatalf("got  %+v\nwant %+v", m, want)
+	}
+	// 2 layers x 2 KV heads x head dim 8 x (K+V) x 2 bytes per token.
+	if got := m.KVCacheBytes(1000); got != 2*2*8*2*2*1000 {
+		t.Fatalf("KVCacheBytes = %d", got)
+	}
+}
+
+func TestReadQuantizationFromTensors(t *testing.T) {
+	m, err := Read(bytes.NewReader(llamaHeader(false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.FileType != -1 || m.Quantization != "Q4_K" {
+		t.Fatalf("file type %d, quantization %q; want the dominant tensor type", m.FileType, m.Quantization)
+	}
+}
+
+func TestReadRejectsBadHeaders(t *testing.T) {
+	full := llamaHeader(true)
+	if _, err := Read(strings.NewReader("PK\x03\x04 not gguf")); !errors.Is(err, ErrNotGGUF) {
+		t.Errorf("zip: %v", err)
+	}
+	if _, err := Read(bytes.NewReader(full[:len(full)-10])); err == nil || !strings.Contains(err.Error(), "truncated") {
+		t.Errorf("truncated: %v", err)
+	}
+
+	var w writer
+	w.u32(magic)
+	w.u32(3)
+	w.u64(1 << 40)
+	w.u64(0)
+	if _, err := Read(bytes.NewReader(w.Bytes())); err == nil {
+		t.Error("implausible tensor count accepted")
+	}
+}
@@ -0,0 +1,120 @@
+package gpu
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rocmSMIArgs requests every field parseROCmSMI reads in one rocm-smi run.
+var rocmSMIArgs = []string{
+	"--showproductname", "--showuniqueid", "--showdriverversion",
+	"--showuse", "--showmeminfo", "vram", "--showtemp", "--showpower", "--showmaxpower",
+	"--json",
+}
+
+// parseROCmSMI converts `rocm-smi --json` output into GPUInfo with one Device
+// per "cardN" entry. Key names vary in case and wording across ROCm releases,
+// so lookups are case-insensitive and try the known variants in order.
+func parseROCmSMI(output []byte) (*GPUInfo, error) {
+	// Older rocm-smi releases print warnings before the JSON document.
+	if i := bytes.IndexByte(output, '{'); i > 0 {
+		output = output[i:]
+	}
+
+	var raw map[string]map[string]interface{}
+	if err := json.Unmarshal(output, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse rocm-smi output: %w", err)
+	}
+
+	info := &GPUInfo{Vendor: VendorAMD, CUDAVersion: "N/A"}
+	if system, ok := raw["system"]; ok {
+		info.DriverVersion = rocmField(system, "driver version")
+	}
+
+	for key, fields := range raw {
+		index, ok := rocmCardIndex(key)
+		if !ok {
+			continue
+		}
+		if info.DriverVersion == "" {
+			info.DriverVersion = rocmField(fields, "driver version")
+		}
+		info.Devices = append(info.Devices, parseROCmDevice(index, fields))
+	}
+	if len(info.Devices) == 0 {
+		return nil, fmt.Errorf("no GPUs found")
+	}
+	sort.Slice(info.Devices, func(i, j int) bool { return info.Devices[i].Index < info.Devices[j].Index })
+
+	info.GPUCount = len(info.Devices)
+	summarize(info)
+	return info, nil
+}
+
+func parseROCmDevice(index int, fields map[string]interface{}) Device {
+	const mib = 1024 * 1024
+	total := int64(parseReading(rocmField(fields, "vram total memory (b)")))
+	used := int64(parseReading(rocmField(fields, "vram total used memory (b)")))
+	utilization := parseReading(rocmField(fields, "gpu use (%)"))
+
+	// Instinct MI300 parts have no edge sensor; junction is the closest reading.
+	temperature := parseReading(rocmField(fields,
+		"temperature (sensor edge) (c)", "temperature (sensor junction) (c)"))
+	// ROCm 6 renamed the average power field for MI300.
+	powerDraw := parseReading(rocmField(fields,
+		"average graphics package power (w)", "current socket graphics package power (w)"))
+
+	name := rocmField(fields, "card series", "card model")
+	if name == "" {
+		name = "AMD GPU"
+	}
+
+	return Device{
+		Index:               index,
+		UUID:                rocmField(fields, "unique id"),
+		ProductName:         name,
+		MemoryTotal:         total / mib,
+		MemoryUsed:          used / mib,
+		MemoryFree:          (total - used) / mib,
+		Utilization:         utilization,
+		UtilizationSmoothed: utilization,
+		Temperature:         temperature,
+		PowerDraw:           powerDraw,
+		PowerLimit:          parseReading(rocmField(fields, "max graphics package power (w)")),
+		InUse:               true,
+		IsBusy:              utilization > busyUtilizationThreshold,
+	}
+}
+
+// rocmCardIndex extracts N from a "cardN" key.
+func rocmCardIndex(key string) (int, bool) {
+	rest, ok := strings.CutPrefix(strings.ToLower(key), "card")
+	if !ok {
+		return 0, false
+	}
+	index, err := strconv.Atoi(rest)
+	if err != nil {
+		return 0, false
+	}
+	return index, true
+}
+
+// rocmField returns the first non-empty value among names, matched case-insensitively.
+// rocm-smi reports values as strings, but numbers are accepted too.
+func rocmField(fields map[string]interface{}, names ...string) string {
+	for _, name := range names {
+		for key, value := range fields {
+			if !strings.EqualFold(key, name) || value == nil {
+				continue
+			}
+			if s := strings.TrimSpace(fmt.Sprint(value)); s != "" && s != "N/A" {
+				return s
+			}
+		}
+	}
+	return ""
+}
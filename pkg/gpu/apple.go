@@ -0,0 +1,77 @@
+package gpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parseSystemProfiler converts `system_profiler SPDisplaysDataType` output.
+// macOS exposes no memory or utilization figures, so those stay zero.
+func parseSystemProfiler(output []byte) (*GPUInfo, error) {
+	// Parse the output
+	outputStr := string(output)
+	lines := strings.Split(outputStr, "\n")
+
+	var productName string
+	var coreCount int
+
+	// Parse the output to extract GPU information
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+
+		// Look for the GPU model line (usually starts with "Chipset Model:")
+		if strings.Contains(line, "Chipset Model:") {
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) == 2 {
+				productName = strings.TrimSpace(parts[1])
+			}
+		}
+
+		// Look for the core count line (usually "Total Number of Cores:")
+		if strings.Contains(line, "Total Number of Cores:") {
+			parts := strings.SplitN(line, ":", 2)
+			if len(parts) == 2 {
+				countStr := strings.TrimSpace(parts[1])
+				count, err := strconv.Atoi(countStr)
+				if err == nil {
+					coreCount = count
+				}
+			}
+		}
+
+		// If we found a GPU name but didn't find it in the previous iteration,
+		// look at the previous line to get the GPU name from the section header
+		if productName == "" && i > 0 && strings.HasSuffix(strings.TrimSpace(lines[i-1]), ":") {
+			productName = strings.TrimSuffix(strings.TrimSpace(lines[i-1]), ":")
+		}
+	}
+
+	// If we couldn't find a product name, use a default
+	if productName == "" {
+		productName = "Unknown macOS GPU"
+	}
+
+	// For macOS, we'll set some default values for fields we can't get
+	gpuInfo := &GPUInfo{
+		Vendor:        VendorApple,
+		ProductName:   productName,
+		DriverVersion: "macOS Native",
+		CUDAVersion:   "N/A",
+		GPUCount:      1,
+		UUID:          "mac-gpu",
+		Utilization:   0.0,
+		MemoryTotal:   0,
+		MemoryUsed:    0,
+		MemoryFree:    0,
+		IsBusy:        false, // Always false for macOS as requested
+	}
+
+	// If we found core count, add it to the product name
+	if coreCount > 0 {
+		gpuInfo.ProductName = fmt.Sprintf("%s (%d cores)", productName, coreCount)
+	}
+	gpuInfo.Devices = []Device{{UUID: gpuInfo.UUID, ProductName: gpuInfo.ProductName, InUse: true}}
+
+	return gpuInfo, nil
+}
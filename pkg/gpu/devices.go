@@ -0,0 +1,111 @@
+package gpu
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Device is a single GPU as reported by nvidia-smi.
+// Temperature is in °C; power readings are in watts (0 when not reported).
+// ThrottleReasons lists the active nvidia-smi clocks_event_reasons.
+// Processes stay local: names of the owner's programs are never reported.
+type Device struct {
+	Index               int       `json:"index"`
+	UUID                string    `json:"uuid"`
+	ProductName         string    `json:"product_name"`
+	MemoryTotal         int64     `json:"memory_total"`
+	MemoryUsed          int64     `json:"memory_used"`
+	MemoryFree          int64     `json:"memory_free"`
+	Utilization         float64   `json:"utilization"`
+	UtilizationSmoothed float64   `json:"utilization_smoothed"`
+	Temperature         float64   `json:"temperature"`
+	PowerDraw           float64   `json:"power_draw"`
+	PowerLimit          float64   `json:"power_limit"`
+	ThrottleReasons     []string  `json:"throttle_reasons,omitempty"`
+	Processes           []Process `json:"-"`
+	InUse               bool      `json:"in_use"`
+	IsBusy              bool      `json:"is_busy"`
+}
+
+// SetDevices restricts busy evaluation and the top-level summary to the GPUs
+// the backend runs on. Each selector is an nvidia-smi index ("0") or a UUID
+// ("GPU-..."), matching CUDA_VISIBLE_DEVICES. Empty means every GPU.
+func (m *Monitor) SetDevices(selectors []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.devices = selectors
+}
+
+// usesDevice reports whether d is one of the backend's GPUs. Callers hold m.mu.
+func (m *Monitor) usesDevice(d Device) bool {
+	if len(m.devices) == 0 {
+		return true
+	}
+	for _, sel := range m.devices {
+		sel = strings.TrimSpace(sel)
+		if idx, err := strconv.Atoi(sel); err == nil {
+			if idx == d.Index {
+				return true
+			}
+			continue
+		}
+		if d.UUID != "" && strings.EqualFold(sel, d.UUID) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySelection marks the backend's devices as in use. If no device matches
+// the selectors every device is used, so a typo does not hide all GPUs.
+func (m *Monitor) applySelection(info *GPUInfo) {
+	matched := 0
+	for i := range info.Devices {
+		info.Devices[i].InUse = m.usesDevice(info.Devices[i])
+		if info.Devices[i].InUse {
+			matched++
+		}
+	}
+	if matched == 0 && len(info.Devices) > 0 {
+		logger.Warn("No GPU matches gpu.devices; using every GPU", zap.Strings("devices", m.devices))
+		for i := range info.Devices {
+			info.Devices[i].InUse = true
+		}
+	}
+}
+
+// summarize fills the top-level GPUInfo fields from the in-use devices:
+// memory is summed, utilization averaged, and the node is busy if any of
+// them is busy. Product name and UUID come from the first in-use device.
+func summarize(info *GPUInfo) {
+	var used []Device
+	for _, d := range info.Devices {
+		if d.InUse {
+			used = append(used, d)
+		}
+	}
+	if len(used) == 0 {
+		return
+	}
+
+	info.ProductName = used[0].ProductName
+	info.UUID = used[0].UUID
+	info.MemoryTotal, info.MemoryUsed, info.MemoryFree = 0, 0, 0
+	info.Utilization, info.UtilizationSmoothed = 0, 0
+	info.PowerDraw = 0
+	info.IsBusy = false
+	for _, d := range used {
+		info.MemoryTotal += d.MemoryTotal
+		info.MemoryUsed += d.MemoryUsed
+		info.MemoryFree += d.MemoryFree
+		info.Utilization += d.Utilization
+		info.UtilizationSmoothed += d.UtilizationSmoothed
+		info.PowerDraw += d.PowerDraw
+		info.IsBusy = info.IsBusy || d.IsBusy
+	}
+	info.Utilization /= float64(len(used))
+	info.UtilizationSmoothed /= float64(len(used))
+}
@@ -0,0 +1,79 @@
+package gpu
+
+import (
+	"os"
+	"testing"
+)
+
+func loadFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestParseNvidiaSMIReportsEveryDevice(t *testing.T) {
+	info, err := parseNvidiaSMI(loadFixture(t, "nvidia-smi-2gpu.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.GPUCount != 2 || len(info.Devices) != 2 {
+		t.Fatalf("gpu_count=%d devices=%d, want 2/2", info.GPUCount, len(info.Devices))
+	}
+
+	d0, d1 := info.Devices[0], info.Devices[1]
+	if d0.Index != 0 || d0.UUID != "GPU-aaaaaaaa-0000-0000-0000-000000000000" || d0.MemoryUsed != 70000 {
+		t.Fatalf("device 0 = %+v", d0)
+	}
+	if d0.Temperature != 71 || d0.PowerDraw != 310.45 || d0.PowerLimit != 400 {
+		t.Fatalf("device 0 temp/power = %v/%v/%v", d0.Temperature, d0.PowerDraw, d0.PowerLimit)
+	}
+	// Legacy power_readings with N/A draw
+	if d1.PowerDraw != 0 || d1.PowerLimit != 400 {
+		t.Fatalf("device 1 power = %v/%v, want 0/400", d1.PowerDraw, d1.PowerLimit)
+	}
+
+	if info.MemoryTotal != 2*81920 || info.MemoryUsed != 70010 {
+		t.Fatalf("summary memory total=%d used=%d, want summed across devices", info.MemoryTotal, info.MemoryUsed)
+	}
+	if info.Utilization != 45 || !info.IsBusy {
+		t.Fatalf("summary utilization=%v busy=%v, want 45/true", info.Utilization, info.IsBusy)
+	}
+}
+
+func TestBusyOnlyConsidersBackendDevices(t *testing.T) {
+	fixture := loadFixture(t, "nvidia-smi-2gpu.xml")
+	probe := func() (*GPUInfo, error) { return parseNvidiaSMI(fixture) }
+
+	tests := []struct {
+		name     string
+		devices  []string
+		wantBusy bool
+		wantUsed int64
+	}{
+		{"all devices", nil, true, 70010},
+		{"idle device by index", []string{"1"}, false, 10},
+		{"busy device by uuid", []string{"gpu-aaaaaaaa-0000-0000-0000-000000000000"}, true, 70000},
+		{"no match falls back to all", []string{"7"}, true, 70010},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &Monitor{probe: probe}
+			m.SetDevices(tt.devices)
+			m.sample()
+
+			info, err := m.Snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.IsBusy != tt.wantBusy || info.MemoryUsed != tt.wantUsed {
+				t.Fatalf("busy=%v used=%d, want %v/%d", info.IsBusy, info.MemoryUsed, tt.wantBusy, tt.wantUsed)
+			}
+			if len(info.Devices) != 2 {
+				t.Fatalf("devices = %d, want every GPU reported", len(info.Devices))
+			}
+		})
+	}
+}
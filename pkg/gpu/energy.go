@@ -0,0 +1,27 @@
+package gpu
+
+import "time"
+
+// integrateEnergy adds the energy drawn since the previous sample, holding
+// that sample's power for the whole interval so EnergyJoules never runs
+// backwards, and records the new reading. Callers hold m.mu.
+func (m *Monitor) integrateEnergy(info *GPUInfo, now time.Time) {
+	if !m.sampledAt.IsZero() {
+		m.energy += m.power * now.Sub(m.sampledAt).Seconds()
+	}
+	m.power = info.PowerDraw
+	info.EnergyJoules = m.energy
+}
+
+// EnergyJoules returns the energy drawn by the in-use devices since the
+// sampler started, extrapolated from the last reading to now. It only grows,
+// so the difference between two calls is the energy used in between.
+// GPUs that report no power draw (Apple) always read zero.
+func (m *Monitor) EnergyJoules() float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if m.sampledAt.IsZero() {
+		return 0
+	}
+	return m.energy + m.power*time.Since(m.sampledAt).Seconds()
+}
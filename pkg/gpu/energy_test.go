@@ -0,0 +1,47 @@
+package gpu
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestIntegrateEnergyHoldsPreviousPower(t *testing.T) {
+	m := &Monitor{}
+	start := time.Now()
+	reading := func(watts ...float64) *GPUInfo {
+		info := &GPUInfo{}
+		for i, w := range watts {
+			info.Devices = append(info.Devices, Device{Index: i, PowerDraw: w, InUse: i == 0})
+		}
+		summarize(info)
+		return info
+	}
+
+	// Only in-use devices count: device 1 is excluded.
+	first := reading(200, 300)
+	m.integrateEnergy(first, start)
+	m.sampledAt = start
+	if first.PowerDraw != 200 || first.EnergyJoules != 0 {
+		t.Fatalf("first sample power=%v energy=%v, want 200/0", first.PowerDraw, first.EnergyJoules)
+	}
+
+	second := reading(100, 300)
+	m.integrateEnergy(second, start.Add(10*time.Second))
+	m.sampledAt = start.Add(10 * time.Second)
+	if second.EnergyJoules != 2000 {
+		t.Fatalf("energy = %v J, want 2000 (200 W held for 10 s)", second.EnergyJoules)
+	}
+
+	// Between samples the counter extrapolates from the latest reading.
+	m.sampledAt = time.Now().Add(-5 * time.Second)
+	if got := m.EnergyJoules(); math.Abs(got-2500) > 5 {
+		t.Fatalf("EnergyJoules = %v, want ~2500", got)
+	}
+}
+
+func TestEnergyJoulesZeroBeforeFirstSample(t *testing.T) {
+	if got := (&Monitor{}).EnergyJoules(); got != 0 {
+		t.Fatalf("EnergyJoules = %v, want 0", got)
+	}
+}
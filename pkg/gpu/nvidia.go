@@ -0,0 +1,239 @@
+package gpu
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NvidiaSMI represents the XML output of nvidia-smi
+type NvidiaSMI struct {
+	XMLName       xml.Name `xml:"nvidia_smi_log"`
+	DriverVersion string   `xml:"driver_version"`
+	CUDAVersion   string   `xml:"cuda_version"`
+	AttachedGPUs  string   `xml:"attached_gpus"`
+	GPUs          []GPU    `xml:"gpu"`
+}
+
+// GPU represents a single GPU in the nvidia-smi output
+type GPU struct {
+	ID            string      `xml:"id,attr"`
+	ProductName   string      `xml:"product_name"`
+	UUID          string      `xml:"uuid"`
+	FBMemoryUsage MemoryUsage `xml:"fb_memory_usage"`
+	Utilization   Utilization `xml:"utilization"`
+	Temperature   Temperature `xml:"temperature"`
+	// Drivers before 535 report power_readings; newer ones gpu_power_readings.
+	PowerReadings    PowerReadings `xml:"power_readings"`
+	GPUPowerReadings PowerReadings `xml:"gpu_power_readings"`
+	// Drivers before 530 report clocks_throttle_reasons; newer ones clocks_event_reasons.
+	ClocksEventReasons    ClockReasons `xml:"clocks_event_reasons"`
+	ClocksThrottleReasons ClockReasons `xml:"clocks_throttle_reasons"`
+	Processes             Processes    `xml:"processes"`
+}
+
+// Processes lists the compute and graphics processes running on a GPU
+type Processes struct {
+	ProcessInfo []ProcessInfo `xml:"process_info"`
+}
+
+// ProcessInfo represents a single GPU process in the nvidia-smi output
+type ProcessInfo struct {
+	PID         string `xml:"pid"`
+	Type        string `xml:"type"` // C (compute), G (graphics) or C+G
+	ProcessName string `xml:"process_name"`
+	UsedMemory  string `xml:"used_memory"`
+}
+
+// ClockReasons lists why GPU clocks are reduced, one "Active"/"Not Active" element per reason
+type ClockReasons struct {
+	Reasons []ClockReason `xml:",any"`
+}
+
+// ClockReason is a single clocks_event_reason_* element
+type ClockReason struct {
+	XMLName xml.Name
+	State   string `xml:",chardata"`
+}
+
+// active returns the active reasons without their clocks_event_reason_ prefix
+// (e.g. "sw_power_cap", "hw_thermal_slowdown").
+func (c ClockReasons) active() []string {
+	var reasons []string
+	for _, r := range c.Reasons {
+		if strings.TrimSpace(r.State) != "Active" {
+			continue
+		}
+		name := strings.TrimPrefix(r.XMLName.Local, "clocks_event_reason_")
+		name = strings.TrimPrefix(name, "clocks_throttle_reason_")
+		reasons = append(reasons, name)
+	}
+	return reasons
+}
+
+// Temperature represents GPU temperature information
+type Temperature struct {
+	GPUTemp string `xml:"gpu_temp"`
+}
+
+// PowerReadings represents GPU power information
+type PowerReadings struct {
+	PowerDraw         string `xml:"power_draw"`
+	PowerLimit        string `xml:"power_limit"`
+	CurrentPowerLimit string `xml:"current_power_limit"`
+}
+
+// MemoryUsage represents memory usage information
+type MemoryUsage struct {
+	Total string `xml:"total"`
+	Used  string `xml:"used"`
+	Free  string `xml:"free"`
+}
+
+// Utilization represents GPU utilization information
+type Utilization struct {
+	GPUUtil    string `xml:"gpu_util"`
+	MemoryUtil string `xml:"memory_util"`
+}
+
+// parseNvidiaSMI converts `nvidia-smi -x -q` output into GPUInfo with one
+// Device per <gpu> element. Every device counts as in use until the sampler
+// applies the configured device selection.
+func parseNvidiaSMI(output []byte) (*GPUInfo, error) {
+	var nvidiaSMI NvidiaSMI
+	if err := xml.Unmarshal(output, &nvidiaSMI); err != nil {
+		return nil, fmt.Errorf("failed to parse nvidia-smi output: %w", err)
+	}
+
+	// Extract GPU count
+	gpuCount, err := strconv.Atoi(strings.TrimSpace(nvidiaSMI.AttachedGPUs))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse GPU count: %w", err)
+	}
+
+	// If no GPUs are found, return an error
+	if gpuCount == 0 || len(nvidiaSMI.GPUs) == 0 {
+		return nil, fmt.Errorf("no GPUs found")
+	}
+
+	devices := make([]Device, 0, len(nvidiaSMI.GPUs))
+	for i, gpu := range nvidiaSMI.GPUs {
+		devices = append(devices, parseDevice(i, gpu))
+	}
+
+	gpuInfo := &GPUInfo{
+		Vendor:        VendorNVIDIA,
+		DriverVersion: nvidiaSMI.DriverVersion,
+		CUDAVersion:   nvidiaSMI.CUDAVersion,
+		GPUCount:      gpuCount,
+		Devices:       devices,
+	}
+	summarize(gpuInfo)
+
+	return gpuInfo, nil
+}
+
+// parseDevice converts one <gpu> element. Unparseable readings are left at zero.
+func parseDevice(index int, gpu GPU) Device {
+	memTotal, _ := parseMemoryValue(gpu.FBMemoryUsage.Total)
+	memUsed, _ := parseMemoryValue(gpu.FBMemoryUsage.Used)
+	memFree, _ := parseMemoryValue(gpu.FBMemoryUsage.Free)
+	utilization, _ := parseUtilization(gpu.Utilization.GPUUtil)
+
+	power := gpu.GPUPowerReadings
+	if power.PowerDraw == "" {
+		power = gpu.PowerReadings
+	}
+	powerLimit := power.CurrentPowerLimit
+	if powerLimit == "" {
+		powerLimit = power.PowerLimit
+	}
+	clockReasons := gpu.ClocksEventReasons
+	if len(clockReasons.Reasons) == 0 {
+		clockReasons = gpu.ClocksThrottleReasons
+	}
+
+	return Device{
+		Index:               index,
+		UUID:                gpu.UUID,
+		ProductName:         gpu.ProductName,
+		MemoryTotal:         memTotal,
+		MemoryUsed:          memUsed,
+		MemoryFree:          memFree,
+		Utilization:         utilization,
+		UtilizationSmoothed: utilization, // the sampler smooths across readings
+		Temperature:         parseReading(gpu.Temperature.GPUTemp),
+		PowerDraw:           parseReading(power.PowerDraw),
+		PowerLimit:          parseReading(powerLimit),
+		ThrottleReasons:     clockReasons.active(),
+		Processes:           parseProcesses(gpu.Processes.ProcessInfo),
+		InUse:               true,
+		IsBusy:              utilization > busyUtilizationThreshold,
+	}
+}
+
+// parseProcesses converts <process_info> elements. Processes without a PID are skipped.
+func parseProcesses(infos []ProcessInfo) []Process {
+	var processes []Process
+	for _, p := range infos {
+		pid, err := strconv.Atoi(strings.TrimSpace(p.PID))
+		if err != nil {
+			continue
+		}
+		used, _ := parseMemoryValue(p.UsedMemory)
+		processes = append(processes, Process{
+			PID:        pid,
+			Type:       strings.TrimSpace(p.Type),
+			Name:       strings.TrimSpace(p.ProcessName),
+			UsedMemory: used,
+		})
+	}
+	return processes
+}
+
+// parseMemoryValue parses a memory value string (e.g., "1234 MiB")
+func parseMemoryValue(value string) (int64, error) {
+	parts := strings.Split(value, " ")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid memory value format: %s", value)
+	}
+
+	// Parse numeric part
+	numValue, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse memory value: %w", err)
+	}
+
+	return numValue, nil
+}
+
+// parseReading parses a numeric reading with a unit suffix (e.g., "45 C", "70.12 W").
+// Readings nvidia-smi reports as "N/A" or omits are returned as 0.
+func parseReading(value string) float64 {
+	fields := strings.Fields(value)
+	if len(fields) == 0 {
+		return 0
+	}
+	numValue, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0
+	}
+	return numValue
+}
+
+// parseUtilization parses a utilization value string (e.g., "50 %")
+func parseUtilization(value string) (float64, error) {
+	parts := strings.Split(value, " ")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid utilization format: %s", value)
+	}
+
+	// Parse numeric part
+	numValue, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse utilization: %w", err)
+	}
+
+	return numValue, nil
+}
@@ -0,0 +1,134 @@
+package gpu
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// OwnerPriorityConfig yields the GPU to its owner: while a process other than
+// the inference backend uses a GPU the backend runs on, the node is reported
+// busy and inference is paused. Opt-in; process lists come from nvidia-smi.
+type OwnerPriorityConfig struct {
+	Enabled          bool          `yaml:"enabled"`
+	BackendProcesses []string      `yaml:"backend_processes"`  // name or command-line substrings of the backend
+	IgnoreProcesses  []string      `yaml:"ignore_processes"`   // desktop processes never treated as foreign
+	MinForeignMemory int64         `yaml:"min_foreign_memory"` // MiB a process must hold to count as foreign
+	Cooldown         time.Duration `yaml:"cooldown"`           // how long foreign load must be gone before serving resumes
+	UnloadOllama     bool          `yaml:"unload_ollama"`      // free VRAM by unloading Ollama models when yielding
+}
+
+// DefaultOwnerPriority returns the defaults used when owner priority is enabled.
+// Display servers and compositors always hold a little GPU memory on a desktop,
+// so they are ignored, as is anything below 256 MiB (e.g. a browser tab).
+// BackendProcesses is left empty: it depends on the provider type, see
+// BackendProcessesFor.
+func DefaultOwnerPriority() OwnerPriorityConfig {
+	return OwnerPriorityConfig{
+		IgnoreProcesses:  []string{"Xorg", "Xwayland", "gnome-shell", "kwin_x11", "kwin_wayland", "plasmashell", "mutter"},
+		MinForeignMemory: 256,
+		Cooldown:         time.Minute,
+	}
+}
+
+// BackendProcessesFor returns the default backend process patterns for a
+// provider type, so that on an Ollama node a vLLM process owned by someone
+// else still counts as foreign (and vice versa).
+func BackendProcessesFor(providerType string) []string {
+	switch providerType {
+	case "vllm":
+		return []string{"vllm"}
+	case "ollama":
+		return []string{"ollama"}
+	}
+	return nil
+}
+
+// Process is a compute or graphics process using a GPU.
+type Process struct {
+	PID        int
+	Type       string // C (compute), G (graphics) or C+G
+	Name       string
+	UsedMemory int64 // MiB
+	Foreign    bool
+}
+
+// readCmdline returns a process's command line, or "" if it cannot be read
+// (other PID namespace, non-Linux). vLLM usually shows up as "python3" in
+// nvidia-smi, so the command line is what identifies it.
+var readCmdline = func(pid int) string {
+	data, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return ""
+	}
+	return strings.ReplaceAll(string(data), "\x00", " ")
+}
+
+// SetOwnerPriority enables yielding to foreign GPU processes. onYield, if set,
+// runs in its own goroutine each time the node starts yielding.
+func (m *Monitor) SetOwnerPriority(cfg OwnerPriorityConfig, onYield func()) {
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultOwnerPriority().Cooldown
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.owner = cfg
+	m.onYield = onYield
+}
+
+// classifyProcesses marks foreign processes on every device and counts those
+// on in-use devices into info.ForeignProcesses. Callers hold m.mu.
+func (m *Monitor) classifyProcesses(info *GPUInfo) {
+	info.ForeignProcesses = 0
+	if !m.owner.Enabled {
+		return
+	}
+	for i := range info.Devices {
+		d := &info.Devices[i]
+		for j := range d.Processes {
+			p := &d.Processes[j]
+			p.Foreign = m.owner.isForeign(*p)
+			if p.Foreign && d.InUse {
+				info.ForeignProcesses++
+			}
+		}
+	}
+}
+
+func (c OwnerPriorityConfig) isForeign(p Process) bool {
+	if p.UsedMemory < c.MinForeignMemory {
+		return false
+	}
+	base := p.Name
+	if i := strings.LastIndexAny(base, `/\`); i >= 0 {
+		base = base[i+1:]
+	}
+	for _, ignored := range c.IgnoreProcesses {
+		if strings.EqualFold(base, ignored) {
+			return false
+		}
+	}
+	identity := strings.ToLower(p.Name + " " + readCmdline(p.PID))
+	for _, backend := range c.BackendProcesses {
+		if backend != "" && strings.Contains(identity, strings.ToLower(backend)) {
+			return false
+		}
+	}
+	return true
+}
+
+// firstForeign returns the first foreign process on an in-use device.
+func firstForeign(devices []Device) (Device, Process, bool) {
+	for _, d := range devices {
+		if !d.InUse {
+			continue
+		}
+		for _, p := range d.Processes {
+			if p.Foreign {
+				return d, p, true
+			}
+		}
+	}
+	return Device{}, Process{}, false
+}
@@ -0,0 +1,73 @@
+package gpu
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOwnerPriorityYieldsToForeignProcesses(t *testing.T) {
+	orig := readCmdline
+	readCmdline = func(pid int) string {
+		if pid == 4242 {
+			return "python3 -m vllm.entrypoints.openai.api_server --model Qwen/Qwen3-8B"
+		}
+		return ""
+	}
+	defer func() { readCmdline = orig }()
+
+	fixture := loadFixture(t, "nvidia-smi-2gpu.xml")
+	probe := func() (*GPUInfo, error) { return parseNvidiaSMI(fixture) }
+
+	t.Run("backend and desktop processes are not foreign", func(t *testing.T) {
+		cfg := DefaultOwnerPriority()
+		cfg.Enabled = true
+		cfg.BackendProcesses = BackendProcessesFor("vllm")
+		m := &Monitor{probe: probe}
+		m.SetDevices([]string{"0"})
+		m.SetOwnerPriority(cfg, nil)
+		m.sample()
+
+		info, _ := m.Snapshot()
+		if info.ForeignProcesses != 0 || info.IsBusy || info.Paused {
+			t.Fatalf("foreign=%d busy=%v paused=%v, want idle node (vLLM + Xorg only)", info.ForeignProcesses, info.IsBusy, info.Paused)
+		}
+	})
+
+	t.Run("another backend is foreign on an Ollama node", func(t *testing.T) {
+		cfg := DefaultOwnerPriority()
+		cfg.Enabled = true
+		cfg.BackendProcesses = BackendProcessesFor("ollama")
+		m := &Monitor{probe: probe}
+		m.SetDevices([]string{"0"})
+		m.SetOwnerPriority(cfg, nil)
+		m.sample()
+
+		if info, _ := m.Snapshot(); info.ForeignProcesses != 1 || !info.IsBusy {
+			t.Fatalf("foreign=%d busy=%v, want the owner's vLLM counted", info.ForeignProcesses, info.IsBusy)
+		}
+	})
+
+	t.Run("foreign process pauses serving and runs the yield hook", func(t *testing.T) {
+		yielded := make(chan struct{}, 1)
+		cfg := DefaultOwnerPriority()
+		cfg.Enabled = true
+		cfg.BackendProcesses = BackendProcessesFor("vllm")
+		m := &Monitor{probe: probe}
+		m.SetOwnerPriority(cfg, func() { yielded <- struct{}{} })
+		m.sample()
+
+		info, _ := m.Snapshot()
+		// blender counts; firefox is under min_foreign_memory
+		if info.ForeignProcesses != 1 || !info.IsBusy {
+			t.Fatalf("foreign=%d busy=%v, want 1/true", info.ForeignProcesses, info.IsBusy)
+		}
+		pause := m.Paused()
+		if !pause.Paused || !pause.Yielding || pause.Reason != "GPU 1 in use by the owner" {
+			t.Fatalf("pause = %+v", pause)
+		}
+		if strings.Contains(info.PauseReason, "blender") {
+			t.Fatal("reported pause reason must not name the owner's program")
+		}
+		<-yielded
+	})
+}
@@ -0,0 +1,113 @@
+package gpu
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+// Vendor identifies which tool a Probe reads.
+type Vendor string
+
+const (
+	VendorNVIDIA Vendor = "nvidia"
+	VendorAMD    Vendor = "amd"
+	VendorApple  Vendor = "apple"
+)
+
+// Probe reads the GPUs on this machine from a vendor tool.
+// Implementations do not log; callers decide how to report failures.
+type Probe interface {
+	Vendor() Vendor
+	Probe() (*GPUInfo, error)
+}
+
+// commandProbe runs a vendor tool and parses its output.
+type commandProbe struct {
+	vendor Vendor
+	tool   string
+	args   []string
+	parse  func([]byte) (*GPUInfo, error)
+}
+
+func (p *commandProbe) Vendor() Vendor { return p.vendor }
+
+func (p *commandProbe) Probe() (*GPUInfo, error) {
+	output, err := exec.Command(p.tool, p.args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to run %s: %w", p.tool, err)
+	}
+	return p.parse(output)
+}
+
+// NewNVIDIAProbe reads `nvidia-smi -x -q`.
+func NewNVIDIAProbe() Probe {
+	return &commandProbe{vendor: VendorNVIDIA, tool: "nvidia-smi", args: []string{"-x", "-q"}, parse: parseNvidiaSMI}
+}
+
+// NewAMDProbe reads `rocm-smi --json` (ROCm, AMD Instinct and Radeon).
+func NewAMDProbe() Probe {
+	return &commandProbe{vendor: VendorAMD, tool: "rocm-smi", args: rocmSMIArgs, parse: parseROCmSMI}
+}
+
+// NewAppleProbe reads `system_profiler SPDisplaysDataType` on macOS.
+func NewAppleProbe() Probe {
+	return &commandProbe{vendor: VendorApple, tool: "system_profiler", args: []string{"SPDisplaysDataType"}, parse: parseSystemProfiler}
+}
+
+// DetectProbe picks the probe for this machine: system_profiler on macOS,
+// otherwise nvidia-smi, then rocm-smi, whichever is on PATH first.
+func DetectProbe() (Probe, error) {
+	if runtime.GOOS == "darwin" {
+		if _, err := exec.LookPath("system_profiler"); err != nil {
+			return nil, fmt.Errorf("system_profiler not found: %w", err)
+		}
+		return NewAppleProbe(), nil
+	}
+	if _, err := exec.LookPath("nvidia-smi"); err == nil {
+		return NewNVIDIAProbe(), nil
+	}
+	if _, err := exec.LookPath("rocm-smi"); err == nil {
+		return NewAMDProbe(), nil
+	}
+	return nil, fmt.Errorf("no GPU tool found: install nvidia-smi (NVIDIA) or rocm-smi (AMD)")
+}
+
+// fixtureProbe replays captured tool output through the vendor's parser.
+type fixtureProbe struct {
+	vendor Vendor
+	output []byte
+}
+
+// NewFixtureProbe returns a Probe that parses output captured from the vendor
+// tool (nvidia-smi -x -q, rocm-smi --json or system_profiler) instead of
+// running it, so parsing can be exercised on machines without a GPU.
+func NewFixtureProbe(vendor Vendor, path string) (Probe, error) {
+	if parserFor(vendor) == nil {
+		return nil, fmt.Errorf("unknown GPU vendor %q", vendor)
+	}
+	output, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read GPU fixture: %w", err)
+	}
+	return &fixtureProbe{vendor: vendor, output: output}, nil
+}
+
+func (p *fixtureProbe) Vendor() Vendor { return p.vendor }
+
+func (p *fixtureProbe) Probe() (*GPUInfo, error) {
+	return parserFor(p.vendor)(p.output)
+}
+
+func parserFor(vendor Vendor) func([]byte) (*GPUInfo, error) {
+	switch vendor {
+	case VendorNVIDIA:
+		return parseNvidiaSMI
+	case VendorAMD:
+		return parseROCmSMI
+	case VendorApple:
+		return parseSystemProfiler
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package gpu
+
+import "testing"
+
+func TestFixtureProbeAMD(t *testing.T) {
+	probe, err := NewFixtureProbe(VendorAMD, "testdata/rocm-smi-2gpu.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := probe.Probe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Vendor != VendorAMD || info.DriverVersion != "6.7.0" || info.GPUCount != 2 {
+		t.Fatalf("info = %+v", info)
+	}
+
+	mi210, mi300 := info.Devices[0], info.Devices[1]
+	if mi210.ProductName != "AMD Instinct MI210" || mi210.UUID != "0x2d6e1f2a1b3c4d5e" {
+		t.Fatalf("card0 = %+v", mi210)
+	}
+	if mi210.MemoryTotal != 65520 || mi210.MemoryUsed != 49152 || mi210.Utilization != 87 {
+		t.Fatalf("card0 memory/util = %d/%d/%v", mi210.MemoryTotal, mi210.MemoryUsed, mi210.Utilization)
+	}
+	if mi210.Temperature != 58 || mi210.PowerDraw != 212 || mi210.PowerLimit != 300 {
+		t.Fatalf("card0 temp/power = %v/%v/%v", mi210.Temperature, mi210.PowerDraw, mi210.PowerLimit)
+	}
+	// MI300X: junction temperature and socket power variants
+	if mi300.Temperature != 41 || mi300.PowerDraw != 138 {
+		t.Fatalf("card1 temp/power = %v/%v", mi300.Temperature, mi300.PowerDraw)
+	}
+	if !info.IsBusy {
+		t.Fatal("card0 at 87% should make the node busy")
+	}
+}
+
+func TestFixtureProbeNVIDIAAndApple(t *testing.T) {
+	tests := []struct {
+		vendor  Vendor
+		fixture string
+		product string
+	}{
+		{VendorNVIDIA, "testdata/nvidia-smi-2gpu.xml", "NVIDIA A100-SXM4-80GB"},
+		{VendorApple, "testdata/system_profiler.txt", "Apple M2 Max (38 cores)"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.vendor), func(t *testing.T) {
+			probe, err := NewFixtureProbe(tt.vendor, tt.fixture)
+			if err != nil {
+				t.Fatal(err)
+			}
+			m := NewMonitorWithProbe(probe)
+			info, err := m.Snapshot()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Vendor != tt.vendor || info.ProductName != tt.product {
+				t.Fatalf("vendor=%q product=%q, want %q/%q", info.Vendor, info.ProductName, tt.vendor, tt.product)
+			}
+		})
+	}
+}
+
+func TestFixtureProbeRejectsBadInput(t *testing.T) {
+	if _, err := NewFixtureProbe("intel", "testdata/rocm-smi-2gpu.json"); err == nil {
+		t.Fatal("expected unknown vendor error")
+	}
+	probe, err := NewFixtureProbe(VendorAMD, "testdata/nvidia-smi-2gpu.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := probe.Probe(); err == nil {
+		t.Fatal("expected parse error for XML given to the AMD parser")
+	}
+}
@@ -0,0 +1,153 @@
+package gpu
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// DefaultProtectionCooldown is how long readings must stay healthy before serving resumes.
+const DefaultProtectionCooldown = 2 * time.Minute
+
+// ProtectionConfig pauses serving when a GPU the backend uses overheats,
+// draws too much power or reports clock throttling. Zero limits disable a check.
+type ProtectionConfig struct {
+	MaxTemperature  float64       `yaml:"max_temperature"`  // °C
+	MaxPower        float64       `yaml:"max_power"`        // watts per GPU
+	ThrottleReasons []string      `yaml:"throttle_reasons"` // clocks_event_reasons that pause serving
+	Cooldown        time.Duration `yaml:"cooldown"`
+}
+
+// DefaultProtection pauses above 90°C or on hardware/thermal slowdown.
+// sw_power_cap is left out: GPUs under full load sit at their power cap.
+func DefaultProtection() ProtectionConfig {
+	return ProtectionConfig{
+		MaxTemperature:  90,
+		ThrottleReasons: []string{"hw_slowdown", "hw_thermal_slowdown", "sw_thermal_slowdown", "hw_power_brake_slowdown"},
+		Cooldown:        DefaultProtectionCooldown,
+	}
+}
+
+// PauseState reports whether inference is held back by protection or by
+// owner priority (Yielding). ResumeAt moves forward with every reading that
+// still trips a limit.
+type PauseState struct {
+	Paused   bool
+	Yielding bool
+	Reason   string
+	Since    time.Time
+	ResumeAt time.Time
+}
+
+// SetProtection sets the limits checked after every sample.
+func (m *Monitor) SetProtection(cfg ProtectionConfig) {
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultProtectionCooldown
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.protection = cfg
+}
+
+// staleSamples is how many sample intervals may pass without a reading before
+// the last one no longer counts as still tripping a limit.
+const staleSamples = 3
+
+// Paused returns the current protection state. A pause whose cool-down has
+// passed is not reported once readings have stopped arriving, so a GPU that
+// can no longer be probed does not hold inference back forever.
+func (m *Monitor) Paused() PauseState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	now := time.Now()
+	if m.pause.Paused && !now.Before(m.pause.ResumeAt) && m.readingsStale(now) {
+		return PauseState{}
+	}
+	return m.pause
+}
+
+// readingsStale reports whether the snapshot is older than staleSamples
+// sample intervals. Callers hold m.mu.
+func (m *Monitor) readingsStale(now time.Time) bool {
+	interval := m.interval
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+	return now.Sub(m.sampledAt) > staleSamples*interval
+}
+
+// expirePause lifts a pause whose cool-down has passed when no reading is
+// available to confirm it, e.g. after the probe started failing. Callers hold m.mu.
+func (m *Monitor) expirePause(now time.Time) {
+	if !m.pause.Paused || now.Before(m.pause.ResumeAt) {
+		return
+	}
+	logger.Warn("Resuming inference after GPU cool-down without a reading", zap.Duration("paused_for", now.Sub(m.pause.Since)))
+	m.pause = PauseState{}
+	if m.snapshot != nil {
+		m.snapshot.Paused = false
+		m.snapshot.PauseReason = ""
+	}
+}
+
+// applyProtection updates the pause state from the in-use devices in info
+// and records it on info. Thermal and power limits take precedence over
+// owner priority. Callers hold m.mu.
+func (m *Monitor) applyProtection(info *GPUInfo, now time.Time) {
+	reason, cooldown, yielding := m.protection.trip(info.Devices), m.protection.Cooldown, false
+	if reason == "" && m.owner.Enabled {
+		if d, p, ok := firstForeign(info.Devices); ok {
+			// The reason is reported to the platform, so it names no program.
+			reason, cooldown, yielding = fmt.Sprintf("GPU %d in use by the owner", d.Index), m.owner.Cooldown, true
+			if !m.pause.Yielding {
+				logger.Warn("Yielding GPU to owner", zap.Int("gpu", d.Index), zap.String("process", p.Name), zap.Int("pid", p.PID))
+				if m.onYield != nil {
+					go m.onYield()
+				}
+			}
+		}
+	}
+
+	switch {
+	case reason != "":
+		if !m.pause.Paused {
+			logger.Warn("Pausing inference", zap.String("reason", reason))
+			m.pause = PauseState{Paused: true, Since: now}
+		}
+		m.pause.Yielding = yielding
+		m.pause.Reason = reason
+		m.pause.ResumeAt = now.Add(cooldown)
+	case m.pause.Paused && !now.Before(m.pause.ResumeAt):
+		logger.Info("Resuming inference after GPU cool-down", zap.Duration("paused_for", now.Sub(m.pause.Since)))
+		m.pause = PauseState{}
+	}
+
+	info.Paused = m.pause.Paused
+	info.PauseReason = m.pause.Reason
+}
+
+// trip returns why the first offending in-use device breaks a limit, or "" if none does.
+func (p ProtectionConfig) trip(devices []Device) string {
+	for _, d := range devices {
+		if !d.InUse {
+			continue
+		}
+		if p.MaxTemperature > 0 && d.Temperature >= p.MaxTemperature {
+			return fmt.Sprintf("GPU %d at %.0f°C (limit %.0f°C)", d.Index, d.Temperature, p.MaxTemperature)
+		}
+		if p.MaxPower > 0 && d.PowerDraw >= p.MaxPower {
+			return fmt.Sprintf("GPU %d drawing %.0fW (limit %.0fW)", d.Index, d.PowerDraw, p.MaxPower)
+		}
+		for _, active := range d.ThrottleReasons {
+			for _, watched := range p.ThrottleReasons {
+				if strings.EqualFold(active, watched) {
+					return fmt.Sprintf("GPU %d throttling (%s)", d.Index, active)
+				}
+			}
+		}
+	}
+	return ""
+}
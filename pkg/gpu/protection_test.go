@@ -0,0 +1,111 @@
+package gpu
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseClockEventReasons(t *testing.T) {
+	info, err := parseNvidiaSMI(loadFixture(t, "nvidia-smi-2gpu.xml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Devices[0].ThrottleReasons; !reflect.DeepEqual(got, []string{"sw_power_cap"}) {
+		t.Fatalf("device 0 reasons = %v, want [sw_power_cap]", got)
+	}
+	// Pre-530 drivers use clocks_throttle_reasons
+	if got := info.Devices[1].ThrottleReasons; !reflect.DeepEqual(got, []string{"gpu_idle"}) {
+		t.Fatalf("device 1 reasons = %v, want [gpu_idle]", got)
+	}
+}
+
+func TestProtectionPausesAndResumesAfterCooldown(t *testing.T) {
+	m := &Monitor{}
+	m.SetProtection(ProtectionConfig{MaxTemperature: 85, ThrottleReasons: []string{"hw_thermal_slowdown"}, Cooldown: time.Minute})
+
+	hot := &GPUInfo{Devices: []Device{{Index: 1, Temperature: 88, InUse: true}}}
+	cool := func() *GPUInfo { return &GPUInfo{Devices: []Device{{Index: 1, Temperature: 60, InUse: true}}} }
+	start := time.Now()
+
+	m.applyProtection(hot, start)
+	if !hot.Paused || hot.PauseReason != "GPU 1 at 88°C (limit 85°C)" {
+		t.Fatalf("paused=%v reason=%q", hot.Paused, hot.PauseReason)
+	}
+
+	// Cool readings inside the cool-down keep serving paused
+	info := cool()
+	m.applyProtection(info, start.Add(30*time.Second))
+	if !info.Paused {
+		t.Fatal("should stay paused during cool-down")
+	}
+
+	info = cool()
+	m.applyProtection(info, start.Add(time.Minute))
+	if info.Paused || m.Paused().Paused {
+		t.Fatal("should resume once the cool-down has passed")
+	}
+}
+
+func TestProtectionPauseEndsWhenProbesFail(t *testing.T) {
+	failing := false
+	m := &Monitor{probe: func() (*GPUInfo, error) {
+		if failing {
+			return nil, errNoSample
+		}
+		return &GPUInfo{Devices: []Device{{Index: 0, Temperature: 95, InUse: true}}}, nil
+	}}
+	m.SetProtection(ProtectionConfig{MaxTemperature: 90, Cooldown: time.Millisecond})
+
+	m.sample()
+	if !m.Paused().Paused {
+		t.Fatal("hot reading should pause")
+	}
+
+	// The GPU drops off the bus: probes fail from now on
+	failing = true
+	time.Sleep(2 * time.Millisecond)
+	m.sample()
+	if m.Paused().Paused {
+		t.Fatal("pause outlived its cool-down with no readings")
+	}
+	if info, err := m.Snapshot(); err != nil || info.Paused {
+		t.Fatalf("snapshot still paused: %+v, %v", info, err)
+	}
+}
+
+func TestPausedIgnoresStaleReadings(t *testing.T) {
+	now := time.Now()
+	m := &Monitor{interval: time.Second}
+	m.pause = PauseState{Paused: true, Reason: "hot", ResumeAt: now.Add(-time.Second)}
+	m.sampledAt = now
+	if !m.Paused().Paused {
+		t.Fatal("fresh readings keep the pause until the next sample")
+	}
+	m.sampledAt = now.Add(-time.Minute)
+	if m.Paused().Paused {
+		t.Fatal("a pause past its cool-down with stale readings should not be reported")
+	}
+}
+
+func TestProtectionTripsOnThrottlingAndIgnoresUnusedDevices(t *testing.T) {
+	p := DefaultProtection()
+
+	devices := []Device{
+		{Index: 0, InUse: true, Temperature: 70, ThrottleReasons: []string{"sw_power_cap"}},
+		{Index: 1, InUse: false, Temperature: 99},
+	}
+	if reason := p.trip(devices); reason != "" {
+		t.Fatalf("power cap and an unused hot GPU should not pause; got %q", reason)
+	}
+
+	devices[0].ThrottleReasons = append(devices[0].ThrottleReasons, "hw_thermal_slowdown")
+	if reason := p.trip(devices); reason != "GPU 0 throttling (hw_thermal_slowdown)" {
+		t.Fatalf("reason = %q", reason)
+	}
+
+	p.MaxPower = 300
+	if reason := p.trip([]Device{{Index: 2, InUse: true, PowerDraw: 310}}); reason != "GPU 2 drawing 310W (limit 300W)" {
+		t.Fatalf("reason = %q", reason)
+	}
+}
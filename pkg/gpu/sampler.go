@@ -0,0 +1,145 @@
+package gpu
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultSampleInterval is how often the sampler refreshes the GPU snapshot.
+	DefaultSampleInterval = 5 * time.Second
+	// DefaultUtilizationSmoothing is the EWMA weight given to each new utilization sample.
+	DefaultUtilizationSmoothing = 0.3
+)
+
+// Start samples the GPU once, then refreshes the cached snapshot every interval
+// until ctx is cancelled. smoothing is the EWMA weight (0-1] for each new
+// utilization sample; out-of-range values use DefaultUtilizationSmoothing.
+func (m *Monitor) Start(ctx context.Context, interval time.Duration, smoothing float64) {
+	if interval <= 0 {
+		interval = DefaultSampleInterval
+	}
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = DefaultUtilizationSmoothing
+	}
+
+	m.mu.Lock()
+	m.smoothing = smoothing
+	m.interval = interval
+	m.started = true
+	m.mu.Unlock()
+
+	m.sample()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				m.sample()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	logger.Debug("GPU sampler started", zap.Duration("interval", interval), zap.Float64("smoothing", smoothing))
+}
+
+// Snapshot returns the most recent sampled GPU information without running
+// nvidia-smi. If the sampler was never started it probes once and caches that.
+func (m *Monitor) Snapshot() (*GPUInfo, error) {
+	m.mu.RLock()
+	started, info, err := m.started, m.snapshot, m.snapshotErr
+	m.mu.RUnlock()
+
+	if info == nil && err == nil && !started {
+		m.sample()
+		m.mu.RLock()
+		info, err = m.snapshot, m.snapshotErr
+		m.mu.RUnlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if info == nil {
+		return nil, errNoSample
+	}
+	out := *info
+	out.Devices = append([]Device(nil), info.Devices...)
+	return &out, nil
+}
+
+// SampledAt returns when the cached snapshot was last refreshed.
+func (m *Monitor) SampledAt() time.Time {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.sampledAt
+}
+
+// sample probes the GPU and folds the reading into the cached snapshot.
+// A failed probe keeps the previous snapshot so one bad nvidia-smi run
+// does not blank the console or health report.
+func (m *Monitor) sample() {
+	info, err := m.probe()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err != nil {
+		logger.Warn("GPU sample failed", zap.Error(err))
+		if m.snapshot == nil {
+			m.snapshotErr = err
+		}
+		m.expirePause(time.Now())
+		return
+	}
+
+	smoothing := m.smoothing
+	if smoothing <= 0 {
+		smoothing = DefaultUtilizationSmoothing
+	}
+	previous := make(map[string]float64)
+	if m.snapshot != nil {
+		for _, d := range m.snapshot.Devices {
+			previous[deviceKey(d)] = d.UtilizationSmoothed
+		}
+	}
+	for i := range info.Devices {
+		d := &info.Devices[i]
+		if prev, ok := previous[deviceKey(*d)]; ok {
+			d.UtilizationSmoothed = smoothing*d.Utilization + (1-smoothing)*prev
+		} else {
+			d.UtilizationSmoothed = d.Utilization
+		}
+		d.IsBusy = !m.isMacOS && d.UtilizationSmoothed > busyUtilizationThreshold
+	}
+	m.applySelection(info)
+	m.classifyProcesses(info)
+	summarize(info)
+	if m.owner.Enabled {
+		// Owner priority replaces the utilization check: only foreign load makes the GPU busy.
+		info.IsBusy = info.ForeignProcesses > 0
+	}
+
+	now := time.Now()
+	m.applyProtection(info, now)
+	m.integrateEnergy(info, now)
+
+	m.snapshot = info
+	m.snapshotErr = nil
+	m.sampledAt = now
+}
+
+// deviceKey identifies a device across samples, preferring its UUID.
+func deviceKey(d Device) string {
+	if d.UUID != "" {
+		return d.UUID
+	}
+	return strconv.Itoa(d.Index)
+}
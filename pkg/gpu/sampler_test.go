@@ -0,0 +1,85 @@
+package gpu
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetDefaultLogger(&logger.Logger{Logger: zap.NewNop()})
+	os.Exit(m.Run())
+}
+
+// scriptedProbe returns the given utilization readings in order.
+func scriptedProbe(readings ...float64) (func() (*GPUInfo, error), *int) {
+	calls := 0
+	return func() (*GPUInfo, error) {
+		u := readings[min(calls, len(readings)-1)]
+		calls++
+		return &GPUInfo{GPUCount: 1, Devices: []Device{{UUID: "GPU-fake", ProductName: "Fake GPU", Utilization: u}}}, nil
+	}, &calls
+}
+
+func TestSamplerSmoothsUtilization(t *testing.T) {
+	probe, _ := scriptedProbe(100, 0, 0)
+	m := &Monitor{probe: probe, smoothing: 0.5}
+
+	m.sample()
+	info, _ := m.Snapshot()
+	if info.UtilizationSmoothed != 100 || !info.IsBusy {
+		t.Fatalf("first sample smoothed=%v busy=%v, want 100/true", info.UtilizationSmoothed, info.IsBusy)
+	}
+
+	m.sample()
+	m.sample()
+	info, _ = m.Snapshot()
+	if info.UtilizationSmoothed != 25 {
+		t.Fatalf("smoothed = %v, want 25 after two idle samples", info.UtilizationSmoothed)
+	}
+	if !info.IsBusy {
+		t.Fatal("smoothed 25% should still be busy (> 20%)")
+	}
+	if info.Utilization != 0 {
+		t.Fatalf("raw utilization = %v, want latest reading 0", info.Utilization)
+	}
+}
+
+func TestSnapshotDoesNotProbeAfterStart(t *testing.T) {
+	probe, calls := scriptedProbe(10)
+	m := &Monitor{probe: probe, started: true}
+	m.sample()
+
+	for i := 0; i < 5; i++ {
+		if _, err := m.Snapshot(); err != nil {
+			t.Fatal(err)
+		}
+		if busy, _ := m.IsBusy(); busy {
+			t.Fatal("10% should not be busy")
+		}
+	}
+	if *calls != 1 {
+		t.Fatalf("probe ran %d times, want 1 (reads must use the cached snapshot)", *calls)
+	}
+}
+
+func TestSampleKeepsLastGoodSnapshotOnError(t *testing.T) {
+	fail := false
+	m := &Monitor{probe: func() (*GPUInfo, error) {
+		if fail {
+			return nil, errors.New("nvidia-smi failed")
+		}
+		return &GPUInfo{Devices: []Device{{ProductName: "Fake GPU", Utilization: 50}}}, nil
+	}}
+	m.sample()
+	fail = true
+	m.sample()
+
+	info, err := m.Snapshot()
+	if err != nil || info.ProductName != "Fake GPU" {
+		t.Fatalf("snapshot = %+v, %v; want last good reading", info, err)
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/sentnl/inferoute-node/inferoute-client/internal/config"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/cloudflare"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/energy"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
@@ -22,19 +23,16 @@ import (
 
 // Reporter handles health reporting to the central system
 type Reporter struct {
-	config           *config.Config
-	gpuMonitor       *gpu.Monitor
-	llmClient        llm.Client
-	cloudflareClient *cloudflare.Client
-	pricingClient    *pricing.Client
-	verifier         *verify.Verifier
-	client           *http.Client
-	lastUpdateTime   time.Time
-	lastUpdateMutex  sync.Mutex
-	// Track registered models to avoid duplicate registrations
-	registeredModels   map[string]bool
-	registeredModelsMu sync.Mutex
-
+	config            *config.Config
+	gpuMonitor        *gpu.Monitor
+	llmClient         llm.Client
+	cloudflareClient  *cloudflare.Client
+	reconciler        *pricing.Reconciler
+	verifier          *verify.Verifier
+	energyMeter       *energy.Meter
+	client            *http.Client
+	lastUpdateTime    time.Time
+	lastUpdateMutex   sync.Mutex
 	displayedModelsMu sync.RWMutex
 	displayedModels   []llm.Model
 }
@@ -50,6 +48,7 @@ type HealthReport struct {
 	GPU          *gpu.GPUInfo           `json:"gpu"`
 	Cloudflare   map[string]interface{} `json:"cloudflare"`
 	ProviderType string                 `json:"provider_type"`
+	Energy       *energy.Stats          `json:"energy,omitempty"`
 }
 
 // SetVerifier attaches the model integrity verifier (optional).
@@ -57,163 +56,28 @@ func (r *Reporter) SetVerifier(v *verify.Verifier) {
 	r.verifier = v
 }
 
+// SetReconciler attaches the model registration reconciler run on every
+// health cycle (optional).
+func (r *Reporter) SetReconciler(rc *pricing.Reconciler) {
+	r.reconciler = rc
+}
+
+// SetEnergyMeter attaches the per-request energy meter (optional).
+func (r *Reporter) SetEnergyMeter(m *energy.Meter) {
+	r.energyMeter = m
+}
+
 // NewReporter creates a new health reporter
 func NewReporter(cfg *config.Config, gpuMonitor *gpu.Monitor, llmClient llm.Client) *Reporter {
 	// Create Cloudflare client using provider API key
 	cloudflareClient := cloudflare.NewClient(cfg.Provider.URL, cfg.Provider.APIKey, cfg.TunnelServiceURL())
 
-	// Create pricing client
-	pricingClient := pricing.NewClient(cfg.Provider.URL, cfg.Provider.APIKey)
-
 	return &Reporter{
 		config:           cfg,
 		gpuMonitor:       gpuMonitor,
 		llmClient:        llmClient,
 		cloudflareClient: cloudflareClient,
-		pricingClient:    pricingClient,
 		client:           &http.Client{Timeout: 10 * time.Second},
-		registeredModels: make(map[string]bool),
-	}
-}
-
-// InitializeRegisteredModels initializes the set of registered models
-// This should be called after the initial model registration at startup
-func (r *Reporter) InitializeRegisteredModels(modelIDs []string) {
-	r.registeredModelsMu.Lock()
-	defer r.registeredModelsMu.Unlock()
-
-	for _, id := range modelIDs {
-		r.registeredModels[id] = true
-	}
-
-	logger.Info("Initialized registered models tracker",
-		zap.Int("model_count", len(modelIDs)))
-}
-
-// registerNewModels checks for new verified models and registers them with pricing
-func (r *Reporter) registerNewModels(ctx context.Context, models []llm.Model) {
-	// Get the current list of verified model IDs
-	currentModelIDs := make([]string, 0, len(models))
-	for _, model := range models {
-		if !verify.IsInferenceAllowed(model.VerificationStatus) {
-			continue
-		}
-		currentModelIDs = append(currentModelIDs, model.ID)
-	}
-
-	// Find new models that need to be registered
-	newModels := make([]string, 0)
-	r.registeredModelsMu.Lock()
-	for _, id := range currentModelIDs {
-		if !r.registeredModels[id] {
-			newModels = append(newModels, id)
-		}
-	}
-	r.registeredModelsMu.Unlock()
-
-	if len(newModels) == 0 {
-		return // No new models to register
-	}
-
-	logger.Info("Found new models to register",
-		zap.Strings("new_models", newModels))
-
-	// Get pricing for the new models
-	prices, err := r.pricingClient.GetModelPrices(ctx, newModels)
-	if err != nil {
-		logger.Error("Failed to get model prices for new models",
-			zap.Error(err),
-			zap.Strings("models", newModels))
-		return
-	}
-
-	// Create a map of model prices for easy lookup and find default pricing
-	priceMap := make(map[string]pricing.ModelPrice)
-	var defaultPrice pricing.ModelPrice
-	for _, price := range prices.ModelPrices {
-		if price.ModelName == "default" {
-			defaultPrice = price
-			continue
-		}
-		priceMap[price.ModelName] = price
-	}
-
-	if defaultPrice.ModelName == "" {
-		logger.Warn("No default pricing found in API response, using hardcoded defaults")
-		defaultPrice = pricing.ModelPrice{
-			ModelName:      "default",
-			AvgInputPrice:  0.0002,
-			AvgOutputPrice: 0.0003,
-		}
-	}
-
-	// Register each new model
-	for _, modelName := range newModels {
-		price, exists := priceMap[modelName]
-		if !exists {
-			logger.Info("No specific pricing found for model, using default pricing",
-				zap.String("model", modelName),
-				zap.Float64("default_input_price", defaultPrice.AvgInputPrice),
-				zap.Float64("default_output_price", defaultPrice.AvgOutputPrice))
-
-			err := r.pricingClient.RegisterModel(ctx, modelName, r.config.Provider.ProviderType,
-				defaultPrice.AvgInputPrice, defaultPrice.AvgOutputPrice)
-
-			if err != nil {
-				// Check if it's a 400 error (model already exists)
-				if resp, ok := err.(*pricing.ErrorResponse); ok && resp.StatusCode == 400 {
-					logger.Info("Model already registered elsewhere",
-						zap.String("model", modelName))
-
-					// Still mark it as registered in our tracker
-					r.registeredModelsMu.Lock()
-					r.registeredModels[modelName] = true
-					r.registeredModelsMu.Unlock()
-					continue
-				}
-
-				logger.Error("Failed to register model with default pricing",
-					zap.String("model", modelName),
-					zap.Error(err))
-				continue
-			}
-		} else {
-			logger.Info("Registering model with specific pricing",
-				zap.String("model", modelName),
-				zap.Float64("input_price", price.AvgInputPrice),
-				zap.Float64("output_price", price.AvgOutputPrice),
-				zap.Int("sample_size", price.SampleSize))
-
-			err := r.pricingClient.RegisterModel(ctx, modelName, r.config.Provider.ProviderType,
-				price.AvgInputPrice, price.AvgOutputPrice)
-
-			if err != nil {
-				// Check if it's a 400 error (model already exists)
-				if resp, ok := err.(*pricing.ErrorResponse); ok && resp.StatusCode == 400 {
-					logger.Info("Model already registered elsewhere",
-						zap.String("model", modelName))
-
-					// Still mark it as registered in our tracker
-					r.registeredModelsMu.Lock()
-					r.registeredModels[modelName] = true
-					r.registeredModelsMu.Unlock()
-					continue
-				}
-
-				logger.Error("Failed to register model",
-					zap.String("model", modelName),
-					zap.Error(err))
-				continue
-			}
-		}
-
-		// Mark model as registered
-		r.registeredModelsMu.Lock()
-		r.registeredModels[modelName] = true
-		r.registeredModelsMu.Unlock()
-
-		logger.Info("Successfully registered new model",
-			zap.String("model", modelName))
 	}
 }
 
@@ -231,8 +95,12 @@ func (r *Reporter) SendHealthReport(ctx context.Context) error {
 		return fmt.Errorf("failed to get health report: %w", err)
 	}
 
-	// Register any new models
-	r.registerNewModels(ctx, report.Data)
+	// Bring platform registrations in line with the verified models
+	if r.reconciler != nil {
+		if _, err := r.reconciler.Reconcile(ctx, report.Data); err != nil {
+			logger.Error("Failed to reconcile model registrations", zap.Error(err))
+		}
+	}
 
 	// Log the Cloudflare section of the report before sending
 	logger.Info("Preparing to send health report with Cloudflare info",
@@ -303,7 +171,7 @@ func (r *Reporter) GetHealthReport(ctx context.Context) (*HealthReport, error) {
 	// Get GPU info if available
 	var gpuInfo *gpu.GPUInfo
 	if r.gpuMonitor != nil {
-		gpuInfo, err = r.gpuMonitor.GetGPUInfo()
+		gpuInfo, err = r.gpuMonitor.Snapshot()
 		if err != nil {
 			logger.Error("Failed to get GPU information", zap.Error(err))
 			gpuInfo = &gpu.GPUInfo{
@@ -356,6 +224,10 @@ func (r *Reporter) GetHealthReport(ctx context.Context) (*HealthReport, error) {
 		Cloudflare:   cloudflareInfo,
 		ProviderType: r.config.Provider.ProviderType,
 	}
+	if r.energyMeter != nil {
+		stats := r.energyMeter.Stats()
+		report.Energy = &stats
+	}
 
 	return report, nil
 }
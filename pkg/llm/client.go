@@ -3,18 +3,22 @@ package llm
 import (
 	"context"
 	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
 )
 
 // Model represents a model in the LLM API
 type Model struct {
-	ID                 string `json:"id"`
-	Object             string `json:"object"`
-	Created            int64  `json:"created"`
-	OwnedBy            string `json:"owned_by"`
-	Digest             string `json:"digest,omitempty"`
-	SizeBytes          int64  `json:"size_bytes,omitempty"`
-	WeightFingerprint  string `json:"weight_fingerprint,omitempty"`
-	VerificationStatus string `json:"verification_status,omitempty"`
+	ID                 string         `json:"id"`
+	Object             string         `json:"object"`
+	Created            int64          `json:"created"`
+	OwnedBy            string         `json:"owned_by"`
+	Digest             string         `json:"digest,omitempty"`
+	SizeBytes          int64          `json:"size_bytes,omitempty"`
+	WeightFingerprint  string         `json:"weight_fingerprint,omitempty"`
+	VerificationStatus string         `json:"verification_status,omitempty"`
+	VerificationReason string         `json:"verification_reason,omitempty"` // set when a local check failed
+	GGUF               *gguf.Metadata `json:"gguf,omitempty"`                // Ollama only: facts from the GGUF header
 }
 
 // ListModelsResponse represents the response from the LLM API for listing models
@@ -65,6 +69,11 @@ type Client interface {
 	// ForwardRequest forwards a raw request to the LLM API
 	// This is used for direct forwarding of OpenAI-compatible requests
 	ForwardRequest(ctx context.Context, path string, body []byte) ([]byte, error)
+
+	// ForwardStream forwards a stream:true request and relays the backend's
+	// server-sent events to w as they arrive. Nothing is written to w when the
+	// backend fails before the stream starts.
+	ForwardStream(ctx context.Context, path string, body []byte, w StreamWriter) error
 }
 
 // NewClient creates a new LLM client based on the provider type
@@ -0,0 +1,54 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// EmbeddingRequest is an OpenAI-compatible /v1/embeddings request.
+// Input may be a string, an array of strings, an array of token IDs or an array of token ID arrays.
+type EmbeddingRequest struct {
+	Model  string          `json:"model"`
+	Input  json.RawMessage `json:"input"`
+	Stream bool            `json:"stream,omitempty"`
+}
+
+// ValidateEmbeddingRequest checks an embeddings request body before it is forwarded.
+// Embeddings are returned in one response, so stream: true is rejected.
+func ValidateEmbeddingRequest(body []byte) error {
+	var req EmbeddingRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		return fmt.Errorf("invalid embeddings request: %w", err)
+	}
+	if req.Stream {
+		return fmt.Errorf("stream is not supported for embeddings")
+	}
+	if len(req.Input) == 0 || string(req.Input) == "null" {
+		return fmt.Errorf("missing input in embeddings request")
+	}
+
+	var single string
+	if err := json.Unmarshal(req.Input, &single); err == nil {
+		return nil
+	}
+	var texts []string
+	if err := json.Unmarshal(req.Input, &texts); err == nil {
+		return nonEmptyInput(len(texts))
+	}
+	var tokens []int
+	if err := json.Unmarshal(req.Input, &tokens); err == nil {
+		return nonEmptyInput(len(tokens))
+	}
+	var tokenBatches [][]int
+	if err := json.Unmarshal(req.Input, &tokenBatches); err == nil {
+		return nonEmptyInput(len(tokenBatches))
+	}
+	return fmt.Errorf("input must be a string, an array of strings or an array of token arrays")
+}
+
+func nonEmptyInput(n int) error {
+	if n == 0 {
+		return fmt.Errorf("input must not be empty")
+	}
+	return nil
+}
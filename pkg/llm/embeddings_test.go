@@ -0,0 +1,26 @@
+package llm
+
+import "testing"
+
+func TestValidateEmbeddingRequest(t *testing.T) {
+	cases := []struct {
+		body    string
+		wantErr bool
+	}{
+		{`{"model":"m","input":"hello"}`, false},
+		{`{"model":"m","input":["a","b","c"]}`, false},
+		{`{"model":"m","input":[1,2,3]}`, false},
+		{`{"model":"m","input":[[1,2],[3]]}`, false},
+		{`{"model":"m","input":"hello","stream":false}`, false},
+		{`{"model":"m","input":"hello","stream":true}`, true},
+		{`{"model":"m","input":[]}`, true},
+		{`{"model":"m"}`, true},
+		{`{"model":"m","input":{"text":"x"}}`, true},
+		{`not json`, true},
+	}
+	for _, tc := range cases {
+		if err := ValidateEmbeddingRequest([]byte(tc.body)); (err != nil) != tc.wantErr {
+			t.Errorf("ValidateEmbeddingRequest(%s) error = %v, wantErr %v", tc.body, err, tc.wantErr)
+		}
+	}
+}
@@ -16,8 +16,9 @@ import (
 
 // OllamaClient implements the LLM Client interface for Ollama
 type OllamaClient struct {
-	baseURL string
-	client  *http.Client
+	baseURL       string
+	client        *http.Client
+	forwardClient *http.Client
 }
 
 // OllamaModel represents the Ollama-specific model format
@@ -39,8 +40,9 @@ type OllamaListModelsResponse struct {
 func NewOllamaClient(baseURL string) Client {
 	logger.Debug("Creating new Ollama client", zap.String("base_url", baseURL))
 	return &OllamaClient{
-		baseURL: baseURL,
-		client:  &http.Client{Timeout: 30 * time.Second},
+		baseURL:       baseURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		forwardClient: newForwardClient(),
 	}
 }
 
@@ -53,6 +55,39 @@ func (c *OllamaClient) ListTags(ctx context.Context) ([]OllamaModel, error) {
 	return ollamaResponse.Models, nil
 }
 
+// UnloadModels asks Ollama to evict every model currently loaded in GPU memory
+// (GET /api/ps, then a keep_alive: 0 generate request per model).
+func (c *OllamaClient) UnloadModels(ctx context.Context) error {
+	var running OllamaListModelsResponse
+	if err := c.getJSON(ctx, fmt.Sprintf("%s/api/ps", c.baseURL), &running); err != nil {
+		return fmt.Errorf("list loaded models: %w", err)
+	}
+
+	for _, m := range running.Models {
+		body, err := json.Marshal(map[string]interface{}{"model": m.Name, "keep_alive": 0})
+		if err != nil {
+			return fmt.Errorf("failed to marshal unload request: %w", err)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/api/generate", c.baseURL), bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("unload %s: %w", m.Name, wrapRequestErr(err))
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("unload %s: %w", m.Name, wrapHTTPStatusErr(resp.StatusCode))
+		}
+		logger.Info("Unloaded Ollama model", zap.String("model", m.Name))
+	}
+	return nil
+}
+
 // ListModels lists all available models
 func (c *OllamaClient) ListModels(ctx context.Context) (*ListModelsResponse, error) {
 	tags, err := c.ListTags(ctx)
@@ -186,31 +221,39 @@ func (c *OllamaClient) Chat(ctx context.Context, request *ChatRequest) (*ChatRes
 
 // ForwardRequest forwards a raw request to the Ollama API
 func (c *OllamaClient) ForwardRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	resp, err := c.forward(ctx, path, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return respBody, nil
+}
+
+// ForwardStream forwards a streaming request to the Ollama API and relays its SSE events
+func (c *OllamaClient) ForwardStream(ctx context.Context, path string, body []byte, w StreamWriter) error {
+	resp, err := c.forward(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return relaySSE(resp.Body, w)
+}
+
+// forward sends a request to the Ollama API and returns the response when the status is 200
+func (c *OllamaClient) forward(ctx context.Context, path string, body []byte) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
 
-	// Parse the request body to transform model name if needed
-	var requestData map[string]interface{}
-	if err := json.Unmarshal(body, &requestData); err != nil {
-		logger.Debug("Failed to parse request body for model transformation", zap.Error(err))
-	} else {
-		// If we have a model field and it's a string, check for gguf/ prefix
-		if modelName, ok := requestData["model"].(string); ok {
-			if strings.HasPrefix(modelName, "gguf/") {
-				// Strip the prefix
-				requestData["model"] = strings.TrimPrefix(modelName, "gguf/")
-				logger.Debug("Stripped gguf/ prefix from model name in forwarded request",
-					zap.String("original_model", modelName),
-					zap.String("transformed_model", requestData["model"].(string)))
-
-				// Re-encode the modified request
-				var err error
-				body, err = json.Marshal(requestData)
-				if err != nil {
-					logger.Error("Failed to re-encode request body after model transformation", zap.Error(err))
-					return nil, fmt.Errorf("failed to re-encode request body: %w", err)
-				}
-			}
-		}
+	body, err := stripGGUFPrefix(body)
+	if err != nil {
+		return nil, err
 	}
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
@@ -227,22 +270,46 @@ func (c *OllamaClient) ForwardRequest(ctx context.Context, path string, body []b
 		zap.String("request", string(body)))
 
 	// Send request
-	resp, err := c.client.Do(req)
+	resp, err := c.forwardClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("forward: %w", wrapRequestErr(err))
 	}
-	defer resp.Body.Close()
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, fmt.Errorf("forward: %w", wrapHTTPStatusErr(resp.StatusCode))
 	}
 
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+	return resp, nil
+}
+
+// stripGGUFPrefix rewrites the model field from gguf/<name> to <name> for Ollama
+func stripGGUFPrefix(body []byte) ([]byte, error) {
+	// Parse the request body to transform model name if needed
+	var requestData map[string]interface{}
+	if err := json.Unmarshal(body, &requestData); err != nil {
+		logger.Debug("Failed to parse request body for model transformation", zap.Error(err))
+		return body, nil
 	}
 
-	return respBody, nil
+	// If we have a model field and it's a string, check for gguf/ prefix
+	modelName, ok := requestData["model"].(string)
+	if !ok || !strings.HasPrefix(modelName, "gguf/") {
+		return body, nil
+	}
+
+	// Strip the prefix
+	requestData["model"] = strings.TrimPrefix(modelName, "gguf/")
+	logger.Debug("Stripped gguf/ prefix from model name in forwarded request",
+		zap.String("original_model", modelName),
+		zap.String("transformed_model", requestData["model"].(string)))
+
+	// Re-encode the modified request
+	out, err := json.Marshal(requestData)
+	if err != nil {
+		logger.Error("Failed to re-encode request body after model transformation", zap.Error(err))
+		return nil, fmt.Errorf("failed to re-encode request body: %w", err)
+	}
+	return out, nil
 }
@@ -20,7 +20,7 @@ func TestMain(m *testing.M) {
 }
 
 func newOllama(baseURL string) *OllamaClient {
-	return &OllamaClient{baseURL: baseURL, client: &http.Client{Timeout: 5 * time.Second}}
+	return &OllamaClient{baseURL: baseURL, client: &http.Client{Timeout: 5 * time.Second}, forwardClient: newForwardClient()}
 }
 
 func TestForwardRequestStripsGgufPrefix(t *testing.T) {
@@ -80,3 +80,31 @@ func TestForwardRequestNon200IsHTTPError(t *testing.T) {
 		t.Fatal("expected error on non-200 status")
 	}
 }
+
+func TestUnloadModelsEvictsEveryLoadedModel(t *testing.T) {
+	var unloaded []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/ps":
+			w.Write([]byte(`{"models":[{"name":"llama3:latest"},{"name":"qwen3:8b"}]}`))
+		case "/api/generate":
+			var parsed map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&parsed)
+			if parsed["keep_alive"] != float64(0) {
+				t.Errorf("keep_alive = %v, want 0", parsed["keep_alive"])
+			}
+			unloaded = append(unloaded, parsed["model"].(string))
+			w.Write([]byte(`{"done":true}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	if err := newOllama(ts.URL).UnloadModels(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(unloaded) != 2 || unloaded[0] != "llama3:latest" || unloaded[1] != "qwen3:8b" {
+		t.Fatalf("unloaded = %v", unloaded)
+	}
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// StreamWriter receives server-sent events relayed from the backend.
+// Flush is called at every event boundary so tokens reach the consumer immediately.
+type StreamWriter interface {
+	io.Writer
+	Flush() error
+}
+
+var sseDone = []byte("data: [DONE]")
+
+// forwardHeaderTimeout bounds the wait for a backend's response headers. It is
+// a backstop for routes whose deadline is disabled: non-streamed generations
+// only send headers when done, so it is sized well above the default deadline.
+const forwardHeaderTimeout = 30 * time.Minute
+
+// newForwardClient returns an HTTP client without an overall timeout for
+// forwarded inference. The caller's context carries the per-route and
+// per-model deadline, so long generations are not cut off by a fixed client
+// timeout; a backend that never answers still is, by forwardHeaderTimeout.
+func newForwardClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = forwardHeaderTimeout
+	return &http.Client{Transport: transport}
+}
+
+// IsStreamRequest reports whether an OpenAI-style request body asks for stream: true.
+func IsStreamRequest(body []byte) bool {
+	var payload struct {
+		Stream bool `json:"stream"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return false
+	}
+	return payload.Stream
+}
+
+// ParseUsage extracts the OpenAI usage object from a response body or a
+// single "data: {...}" stream event. Streams only carry usage in the final
+// chunk, and only when the consumer asked for stream_options.include_usage.
+func ParseUsage(data []byte) (TokenUsage, bool) {
+	data = bytes.TrimSpace(bytes.TrimPrefix(bytes.TrimSpace(data), []byte("data:")))
+	if !bytes.Contains(data, []byte(`"usage"`)) {
+		return TokenUsage{}, false
+	}
+	var payload struct {
+		Usage *TokenUsage `json:"usage"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil || payload.Usage == nil {
+		return TokenUsage{}, false
+	}
+	return *payload.Usage, true
+}
+
+// relaySSE copies server-sent events from body to w line by line, flushing at
+// each blank-line event boundary. It returns after the [DONE] terminator or EOF.
+func relaySSE(body io.Reader, w StreamWriter) error {
+	reader := bufio.NewReader(body)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			trimmed := bytes.TrimSpace(line)
+			if bytes.Equal(trimmed, sseDone) {
+				if _, werr := w.Write([]byte("data: [DONE]\n\n")); werr != nil {
+					return fmt.Errorf("write stream: %w", werr)
+				}
+				return w.Flush()
+			}
+			if _, werr := w.Write(line); werr != nil {
+				return fmt.Errorf("write stream: %w", werr)
+			}
+			if len(trimmed) == 0 {
+				if ferr := w.Flush(); ferr != nil {
+					return fmt.Errorf("flush stream: %w", ferr)
+				}
+			}
+		}
+		if err == io.EOF {
+			return w.Flush()
+		}
+		if err != nil {
+			return fmt.Errorf("read stream: %w", wrapRequestErr(err))
+		}
+	}
+}
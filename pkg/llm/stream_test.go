@@ -0,0 +1,116 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// recordingStream is a StreamWriter that records what was flushed.
+type recordingStream struct {
+	buf     bytes.Buffer
+	flushed []string
+}
+
+func (r *recordingStream) Write(p []byte) (int, error) { return r.buf.Write(p) }
+func (r *recordingStream) Flush() error {
+	r.flushed = append(r.flushed, r.buf.String())
+	return nil
+}
+
+func TestRelaySSEFlushesPerEventAndStopsAtDone(t *testing.T) {
+	in := "data: {\"a\":1}\n\ndata: {\"a\":2}\n\ndata: [DONE]\n\ndata: ignored\n\n"
+	w := &recordingStream{}
+	if err := relaySSE(strings.NewReader(in), w); err != nil {
+		t.Fatal(err)
+	}
+	want := "data: {\"a\":1}\n\ndata: {\"a\":2}\n\ndata: [DONE]\n\n"
+	if w.buf.String() != want {
+		t.Fatalf("relayed = %q, want %q", w.buf.String(), want)
+	}
+	if len(w.flushed) != 3 {
+		t.Fatalf("flush count = %d, want one per event (3)", len(w.flushed))
+	}
+	if w.flushed[0] != "data: {\"a\":1}\n\n" {
+		t.Fatalf("first flush = %q, want first event only", w.flushed[0])
+	}
+}
+
+func TestIsStreamRequest(t *testing.T) {
+	cases := map[string]bool{
+		`{"model":"m","stream":true}`:  true,
+		`{"model":"m","stream":false}`: false,
+		`{"model":"m"}`:                false,
+		`not json`:                     false,
+	}
+	for body, want := range cases {
+		if got := IsStreamRequest([]byte(body)); got != want {
+			t.Errorf("IsStreamRequest(%s) = %v, want %v", body, got, want)
+		}
+	}
+}
+
+func TestParseUsage(t *testing.T) {
+	cases := []struct {
+		data string
+		want TokenUsage
+		ok   bool
+	}{
+		{`{"choices":[],"usage":{"prompt_tokens":12,"completion_tokens":30,"total_tokens":42}}`, TokenUsage{12, 30, 42}, true},
+		{"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":5,\"completion_tokens\":7,\"total_tokens\":12}}\n", TokenUsage{5, 7, 12}, true},
+		{`data: {"choices":[{"delta":{"content":"hi"}}],"usage":null}`, TokenUsage{}, false},
+		{`data: {"choices":[{"delta":{"content":"hi"}}]}`, TokenUsage{}, false},
+		{`data: [DONE]`, TokenUsage{}, false},
+	}
+	for _, c := range cases {
+		got, ok := ParseUsage([]byte(c.data))
+		if ok != c.ok || got != c.want {
+			t.Errorf("ParseUsage(%q) = %+v, %v; want %+v, %v", c.data, got, ok, c.want, c.ok)
+		}
+	}
+}
+
+func TestForwardStreamOllama(t *testing.T) {
+	t.Run("relays SSE with gguf/ stripped", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var body bytes.Buffer
+			body.ReadFrom(r.Body)
+			if !strings.Contains(body.String(), `"model":"llama3"`) {
+				t.Errorf("forwarded body = %s, want gguf/ stripped", body.String())
+			}
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Write([]byte("data: {\"x\":1}\n\n"))
+			w.(http.Flusher).Flush()
+			w.Write([]byte("data: [DONE]\n\n"))
+		}))
+		defer ts.Close()
+
+		w := &recordingStream{}
+		if err := newOllama(ts.URL).ForwardStream(context.Background(), "/v1/chat/completions", []byte(`{"model":"gguf/llama3","stream":true}`), w); err != nil {
+			t.Fatal(err)
+		}
+		if w.buf.String() != "data: {\"x\":1}\n\ndata: [DONE]\n\n" {
+			t.Fatalf("relayed = %q", w.buf.String())
+		}
+	})
+
+	t.Run("non-200 writes nothing and is an HTTP error", func(t *testing.T) {
+		ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer ts.Close()
+
+		w := &recordingStream{}
+		err := newOllama(ts.URL).ForwardStream(context.Background(), "/v1/completions", []byte(`{"model":"m","stream":true}`), w)
+		if !errors.Is(err, ErrHTTP) {
+			t.Fatalf("error = %v, want ErrHTTP", err)
+		}
+		if w.buf.Len() != 0 {
+			t.Fatalf("expected nothing written, got %q", w.buf.String())
+		}
+	})
+}
@@ -15,16 +15,18 @@ import (
 
 // VLLMClient implements the LLM Client interface for vLLM
 type VLLMClient struct {
-	baseURL string
-	client  *http.Client
+	baseURL       string
+	client        *http.Client
+	forwardClient *http.Client
 }
 
 // NewVLLMClient creates a new vLLM client
 func NewVLLMClient(baseURL string) Client {
 	logger.Debug("Creating new vLLM client", zap.String("base_url", baseURL))
 	return &VLLMClient{
-		baseURL: baseURL,
-		client:  &http.Client{Timeout: 30 * time.Second},
+		baseURL:       baseURL,
+		client:        &http.Client{Timeout: 30 * time.Second},
+		forwardClient: newForwardClient(),
 	}
 }
 
@@ -132,6 +134,34 @@ func (c *VLLMClient) Chat(ctx context.Context, request *ChatRequest) (*ChatRespo
 
 // ForwardRequest forwards a raw request to the vLLM API
 func (c *VLLMClient) ForwardRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	resp, err := c.forward(ctx, path, body)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	return respBody, nil
+}
+
+// ForwardStream forwards a streaming request to the vLLM API and relays its SSE events
+func (c *VLLMClient) ForwardStream(ctx context.Context, path string, body []byte, w StreamWriter) error {
+	resp, err := c.forward(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return relaySSE(resp.Body, w)
+}
+
+// forward sends a request to the vLLM API and returns the response when the status is 200
+func (c *VLLMClient) forward(ctx context.Context, path string, body []byte) (*http.Response, error) {
 	url := fmt.Sprintf("%s%s", c.baseURL, path)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(body))
@@ -143,22 +173,16 @@ func (c *VLLMClient) ForwardRequest(ctx context.Context, path string, body []byt
 	req.Header.Set("Content-Type", "application/json")
 
 	// Send request
-	resp, err := c.client.Do(req)
+	resp, err := c.forwardClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("forward: %w", wrapRequestErr(err))
 	}
-	defer resp.Body.Close()
 
 	// Check response status
 	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
 		return nil, fmt.Errorf("forward: %w", wrapHTTPStatusErr(resp.StatusCode))
 	}
 
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
-	}
-
-	return respBody, nil
+	return resp, nil
 }
@@ -0,0 +1,146 @@
+package pricing
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// secondsPerMonth is an average month (365.25 / 12 days).
+const secondsPerMonth = 30.4375 * 24 * 3600
+
+// AdvisorConfig describes what the node costs to own, for `pricing advise`.
+// Electricity comes from energy.price_per_kwh.
+type AdvisorConfig struct {
+	HardwareCost       float64 `yaml:"hardware_cost"`       // USD paid for the GPUs / node
+	AmortizationMonths float64 `yaml:"amortization_months"` // period hardware_cost is written off over
+	Utilization        float64 `yaml:"utilization"`         // expected share of time spent serving (0-1]
+}
+
+// DefaultAdvisor writes hardware off over three years at 50% serving time.
+func DefaultAdvisor() AdvisorConfig {
+	return AdvisorConfig{AmortizationMonths: 36, Utilization: 0.5}
+}
+
+// Costs are what break-even prices must cover.
+type Costs struct {
+	PricePerKWh float64
+	Hardware    AdvisorConfig
+}
+
+// perSecond returns the USD cost of one second of serving at the given power draw.
+// Hardware is amortized over serving time only, so lower utilization raises it.
+func (c Costs) perSecond(watts float64) float64 {
+	cost := watts / 1000 / 3600 * c.PricePerKWh
+	hw := c.Hardware
+	if hw.HardwareCost > 0 && hw.AmortizationMonths > 0 && hw.Utilization > 0 {
+		cost += hw.HardwareCost / (hw.AmortizationMonths * secondsPerMonth) / hw.Utilization
+	}
+	return cost
+}
+
+// Throughput is one model's measured speed and power on the local backend.
+type Throughput struct {
+	Model              string  `json:"model"`
+	PromptTokensPerSec float64 `json:"prompt_tokens_per_sec"`
+	OutputTokensPerSec float64 `json:"output_tokens_per_sec"`
+	PromptWatts        float64 `json:"prompt_watts"`
+	OutputWatts        float64 `json:"output_watts"`
+}
+
+// BreakEven returns the USD per input and output token at which serving at
+// the measured throughput covers electricity and hardware amortization.
+func BreakEven(t Throughput, c Costs) (input, output float64) {
+	if t.PromptTokensPerSec > 0 {
+		input = c.perSecond(t.PromptWatts) / t.PromptTokensPerSec
+	}
+	if t.OutputTokensPerSec > 0 {
+		output = c.perSecond(t.OutputWatts) / t.OutputTokensPerSec
+	}
+	return input, output
+}
+
+// Advice compares a model's break-even prices with the platform average.
+// Prices are USD per token, as registered with the platform.
+type Advice struct {
+	Throughput
+	BreakEvenInputPrice  float64 `json:"break_even_input_price"`
+	BreakEvenOutputPrice float64 `json:"break_even_output_price"`
+	MarketInputPrice     float64 `json:"market_input_price"`
+	MarketOutputPrice    float64 `json:"market_output_price"`
+	MarketSampleSize     int     `json:"market_sample_size"`
+	MarketSource         string  `json:"market_source"` // "model", "default" or "none"
+	Profitable           bool    `json:"profitable"`    // market prices cover both break-even prices
+}
+
+// Advise computes break-even prices for each measured model and compares
+// them with the model's platform average, or the "default" entry if the
+// platform has no price for it yet.
+func Advise(throughputs []Throughput, prices *GetPricesResponse, c Costs) []Advice {
+	advice := make([]Advice, 0, len(throughputs))
+	for _, t := range throughputs {
+		a := Advice{Throughput: t, MarketSource: "none"}
+		a.BreakEvenInputPrice, a.BreakEvenOutputPrice = BreakEven(t, c)
+		if market, source, ok := prices.lookup(t.Model); ok {
+			a.MarketInputPrice = market.AvgInputPrice
+			a.MarketOutputPrice = market.AvgOutputPrice
+			a.MarketSampleSize = market.SampleSize
+			a.MarketSource = source
+			a.Profitable = market.AvgInputPrice >= a.BreakEvenInputPrice && market.AvgOutputPrice >= a.BreakEvenOutputPrice
+		}
+		advice = append(advice, a)
+	}
+	return advice
+}
+
+// lookup returns the price for model, falling back to the "default" entry.
+func (r *GetPricesResponse) lookup(model string) (ModelPrice, string, bool) {
+	if r == nil {
+		return ModelPrice{}, "", false
+	}
+	var fallback *ModelPrice
+	for i, p := range r.ModelPrices {
+		switch p.ModelName {
+		case model:
+			return p, "model", true
+		case "default":
+			fallback = &r.ModelPrices[i]
+		}
+	}
+	if fallback == nil {
+		return ModelPrice{}, "", false
+	}
+	return *fallback, "default", true
+}
+
+// WriteAdviceJSON writes advice as indented JSON.
+func WriteAdviceJSON(w io.Writer, advice []Advice) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(advice)
+}
+
+// WriteAdviceTable writes advice as a table with prices per 1M tokens,
+// the unit used in the dashboard.
+func WriteAdviceTable(w io.Writer, advice []Advice) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tPROMPT TOK/S\tOUTPUT TOK/S\tWATTS\tBREAK-EVEN IN/OUT ($/1M)\tMARKET IN/OUT ($/1M)\tVERDICT")
+	for _, a := range advice {
+		market, verdict := "-", "no market price"
+		if a.MarketSource != "none" {
+			market = fmt.Sprintf("%.4f / %.4f", a.MarketInputPrice*1e6, a.MarketOutputPrice*1e6)
+			if a.MarketSource == "default" {
+				market += " (default)"
+			}
+			verdict = "below break-even"
+			if a.Profitable {
+				verdict = "profitable"
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%.0f\t%.1f\t%.0f\t%.4f / %.4f\t%s\t%s\n",
+			a.Model, a.PromptTokensPerSec, a.OutputTokensPerSec, a.OutputWatts,
+			a.BreakEvenInputPrice*1e6, a.BreakEvenOutputPrice*1e6, market, verdict)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,113 @@
+package pricing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestBreakEven(t *testing.T) {
+	tp := Throughput{Model: "m", PromptTokensPerSec: 2000, OutputTokensPerSec: 40, PromptWatts: 360, OutputWatts: 300}
+
+	// Electricity only: 300 W at $0.30/kWh is $0.000025/s; at 40 tok/s that is $0.000000625/token.
+	in, out := BreakEven(tp, Costs{PricePerKWh: 0.30})
+	if !almostEqual(out, 0.000025/40) || !almostEqual(in, 0.00003/2000) {
+		t.Fatalf("energy-only break-even = %g / %g", in, out)
+	}
+
+	// Hardware: $2630 over 36 months at 50% serving time.
+	hw := AdvisorConfig{HardwareCost: 2630, AmortizationMonths: 36, Utilization: 0.5}
+	hwPerSecond := 2630 / (36 * secondsPerMonth) / 0.5
+	_, out = BreakEven(tp, Costs{Hardware: hw})
+	if !almostEqual(out, hwPerSecond/40) {
+		t.Fatalf("hardware-only output break-even = %g, want %g", out, hwPerSecond/40)
+	}
+
+	if in, out := BreakEven(Throughput{}, Costs{PricePerKWh: 1}); in != 0 || out != 0 {
+		t.Fatalf("unmeasured throughput = %g / %g, want 0", in, out)
+	}
+}
+
+func TestAdviseComparesWithMarket(t *testing.T) {
+	costs := Costs{PricePerKWh: 0.30}
+	throughputs := []Throughput{
+		{Model: "fast", PromptTokensPerSec: 2000, OutputTokensPerSec: 100, PromptWatts: 300, OutputWatts: 300},
+		{Model: "slow", PromptTokensPerSec: 10, OutputTokensPerSec: 1, PromptWatts: 300, OutputWatts: 300},
+	}
+	prices := &GetPricesResponse{ModelPrices: []ModelPrice{
+		{ModelName: "fast", AvgInputPrice: 0.0000001, AvgOutputPrice: 0.000001, SampleSize: 12},
+		{ModelName: "default", AvgInputPrice: 0.0000001, AvgOutputPrice: 0.000001},
+	}}
+
+	advice := Advise(throughputs, prices, costs)
+	if advice[0].MarketSource != "model" || advice[0].MarketSampleSize != 12 || !advice[0].Profitable {
+		t.Fatalf("fast = %+v, want profitable at its own market price", advice[0])
+	}
+	if advice[1].MarketSource != "default" || advice[1].Profitable {
+		t.Fatalf("slow = %+v, want below break-even at the default price", advice[1])
+	}
+
+	none := Advise(throughputs[:1], &GetPricesResponse{}, costs)
+	if none[0].MarketSource != "none" || none[0].Profitable {
+		t.Fatalf("without prices = %+v", none[0])
+	}
+
+	var buf bytes.Buffer
+	if err := WriteAdviceTable(&buf, advice); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "(default)") || !strings.Contains(buf.String(), "below break-even") {
+		t.Fatalf("table = %s", buf.String())
+	}
+}
+
+func TestBenchmarkMeasuresThroughput(t *testing.T) {
+	var maxTokens []int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			MaxTokens int `json:"max_tokens"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		maxTokens = append(maxTokens, req.MaxTokens)
+		time.Sleep(20 * time.Millisecond)
+		if req.MaxTokens == 1 {
+			w.Write([]byte(`{"usage":{"prompt_tokens":1500,"completion_tokens":1,"total_tokens":1501}}`))
+			return
+		}
+		w.Write([]byte(`{"usage":{"prompt_tokens":12,"completion_tokens":64,"total_tokens":76}}`))
+	}))
+	defer ts.Close()
+
+	// A 250 W GPU: the counter advances with wall time.
+	start := time.Now()
+	energy := func() float64 { return 250 * time.Since(start).Seconds() }
+
+	tp, err := Benchmark(context.Background(), llm.NewVLLMClient(ts.URL), "m", 64, energy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(maxTokens) != 3 || maxTokens[2] != 64 {
+		t.Fatalf("requests max_tokens = %v, want warm-up, prompt and 64-token runs", maxTokens)
+	}
+	if tp.PromptTokensPerSec <= 0 || tp.PromptTokensPerSec > 1500/0.02 {
+		t.Fatalf("prompt tok/s = %v", tp.PromptTokensPerSec)
+	}
+	if tp.OutputTokensPerSec <= 0 || tp.OutputTokensPerSec > 64/0.02 {
+		t.Fatalf("output tok/s = %v", tp.OutputTokensPerSec)
+	}
+	if math.Abs(tp.OutputWatts-250) > 1 || math.Abs(tp.PromptWatts-250) > 1 {
+		t.Fatalf("watts = %v / %v, want ~250", tp.PromptWatts, tp.OutputWatts)
+	}
+}
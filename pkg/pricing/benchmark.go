@@ -0,0 +1,87 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+// DefaultBenchmarkTokens is how many tokens the generation run asks for.
+const DefaultBenchmarkTokens = 256
+
+// benchmarkPrompt is long enough (~1.5k tokens) that prompt processing
+// dominates the one-token prompt run.
+var benchmarkPrompt = strings.Repeat("The quick brown fox jumps over the lazy dog. ", 150)
+
+// Benchmark measures model's prompt and output throughput on the local backend
+// with three chat completions: a warm-up that loads the model, a long prompt
+// with a one-token answer, and a short prompt with a maxTokens answer.
+// energy is a cumulative joule counter (gpu.Monitor.EnergyJoules) used for
+// the average power of each run; nil leaves the watts at zero.
+func Benchmark(ctx context.Context, client llm.Client, model string, maxTokens int, energy func() float64) (Throughput, error) {
+	if maxTokens <= 0 {
+		maxTokens = DefaultBenchmarkTokens
+	}
+	t := Throughput{Model: model}
+
+	if _, _, _, err := timedCompletion(ctx, client, model, "Say hello.", 1, energy); err != nil {
+		return t, fmt.Errorf("warm-up: %w", err)
+	}
+
+	usage, elapsed, joules, err := timedCompletion(ctx, client, model, benchmarkPrompt, 1, energy)
+	if err != nil {
+		return t, fmt.Errorf("prompt run: %w", err)
+	}
+	if usage.PromptTokens == 0 {
+		return t, fmt.Errorf("prompt run: backend reported no usage")
+	}
+	t.PromptTokensPerSec = float64(usage.PromptTokens) / elapsed.Seconds()
+	t.PromptWatts = joules / elapsed.Seconds()
+
+	usage, elapsed, joules, err = timedCompletion(ctx, client, model, "Write a short story about a lighthouse keeper.", maxTokens, energy)
+	if err != nil {
+		return t, fmt.Errorf("generation run: %w", err)
+	}
+	if usage.CompletionTokens == 0 {
+		return t, fmt.Errorf("generation run: backend reported no usage")
+	}
+	t.OutputTokensPerSec = float64(usage.CompletionTokens) / elapsed.Seconds()
+	t.OutputWatts = joules / elapsed.Seconds()
+	return t, nil
+}
+
+// timedCompletion runs one non-streaming chat completion and returns its
+// usage, wall time and the joules drawn meanwhile.
+func timedCompletion(ctx context.Context, client llm.Client, model, prompt string, maxTokens int, energy func() float64) (llm.TokenUsage, time.Duration, float64, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"model":       model,
+		"messages":    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		"max_tokens":  maxTokens,
+		"temperature": 0,
+	})
+	if err != nil {
+		return llm.TokenUsage{}, 0, 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	var before float64
+	if energy != nil {
+		before = energy()
+	}
+	start := time.Now()
+	resp, err := client.ForwardRequest(ctx, "/v1/chat/completions", body)
+	elapsed := time.Since(start)
+	if err != nil {
+		return llm.TokenUsage{}, 0, 0, err
+	}
+	var joules float64
+	if energy != nil {
+		joules = energy() - before
+	}
+
+	usage, _ := llm.ParseUsage(resp)
+	return usage, elapsed, joules, nil
+}
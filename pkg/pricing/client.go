@@ -102,53 +102,144 @@ func (c *Client) GetModelPrices(ctx context.Context, models []string) (*GetPrice
 }
 
 func (c *Client) RegisterModel(ctx context.Context, model string, serviceType string, inputPrice, outputPrice float64) error {
-	url := fmt.Sprintf("%s/api/provider/models", c.baseURL)
 	logger.Debug("Registering model",
 		zap.String("model", model),
 		zap.String("service_type", serviceType),
 		zap.Float64("input_price", inputPrice),
 		zap.Float64("output_price", outputPrice))
 
-	request := RegisterModelRequest{
+	resp, err := c.send(ctx, http.MethodPost, "/api/provider/models", RegisterModelRequest{
 		ModelName:         model,
 		ServiceType:       serviceType,
 		InputPriceTokens:  inputPrice,
 		OutputPriceTokens: outputPrice,
+	})
+	if err != nil {
+		return err
 	}
+	defer resp.Body.Close()
 
-	requestJSON, err := json.Marshal(request)
-	if err != nil {
-		return fmt.Errorf("failed to marshal request: %w", err)
+	// Check for error responses
+	if resp.StatusCode >= 400 {
+		return apiError(resp)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(requestJSON))
+	return nil
+}
+
+// RegisteredModel is a model the platform has registered for this provider.
+// Prices are USD per token.
+type RegisteredModel struct {
+	ID                string  `json:"id"`
+	ModelName         string  `json:"model_name"`
+	ServiceType       string  `json:"service_type"`
+	InputPriceTokens  float64 `json:"input_price_tokens"`
+	OutputPriceTokens float64 `json:"output_price_tokens"`
+}
+
+// ListModelsResponse is the platform's list of registered models.
+type ListModelsResponse struct {
+	Models []RegisteredModel `json:"models"`
+}
+
+// ListModels returns the models the platform has registered for this provider.
+func (c *Client) ListModels(ctx context.Context) ([]RegisteredModel, error) {
+	resp, err := c.send(ctx, http.MethodGet, "/api/provider/models", nil)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
+	defer resp.Body.Close()
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+	if resp.StatusCode != http.StatusOK {
+		return nil, apiError(resp)
+	}
 
-	resp, err := c.client.Do(req)
+	var response ListModelsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return response.Models, nil
+}
+
+// UpdateModel changes the prices of a registered model.
+func (c *Client) UpdateModel(ctx context.Context, id, model, serviceType string, inputPrice, outputPrice float64) error {
+	logger.Debug("Updating model",
+		zap.String("model", model),
+		zap.String("id", id),
+		zap.Float64("input_price", inputPrice),
+		zap.Float64("output_price", outputPrice))
+
+	resp, err := c.send(ctx, http.MethodPut, "/api/provider/models/"+id, RegisterModelRequest{
+		ModelName:         model,
+		ServiceType:       serviceType,
+		InputPriceTokens:  inputPrice,
+		OutputPriceTokens: outputPrice,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to send request: %w", err)
+		return err
 	}
 	defer resp.Body.Close()
 
-	// Check for error responses
 	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		var errResp ErrorResponse
-		if err := json.Unmarshal(body, &errResp); err != nil {
-			// If we can't parse the error response, return a generic error
-			return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
-		}
-		errResp.StatusCode = resp.StatusCode
-		if resp.StatusCode == http.StatusBadRequest && strings.Contains(errResp.Message, "already exists") {
-			return ErrModelAlreadyExists
-		}
-		return &errResp
+		return apiError(resp)
 	}
+	return nil
+}
+
+// DeleteModel deregisters a model so it is no longer advertised.
+func (c *Client) DeleteModel(ctx context.Context, id string) error {
+	logger.Debug("Deleting model", zap.String("id", id))
 
+	resp, err := c.send(ctx, http.MethodDelete, "/api/provider/models/"+id, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
+		return apiError(resp)
+	}
 	return nil
 }
+
+// send makes an authenticated request to the platform with an optional JSON body.
+func (c *Client) send(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// apiError converts an error response into ErrModelAlreadyExists or *ErrorResponse.
+func apiError(resp *http.Response) error {
+	body, _ := io.ReadAll(resp.Body)
+	var errResp ErrorResponse
+	if err := json.Unmarshal(body, &errResp); err != nil {
+		// If we can't parse the error response, return a generic error
+		return fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	errResp.StatusCode = resp.StatusCode
+	if resp.StatusCode == http.StatusBadRequest && strings.Contains(errResp.Message, "already exists") {
+		return ErrModelAlreadyExists
+	}
+	return &errResp
+}
@@ -0,0 +1,273 @@
+package pricing
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// loadSampleInterval is how often the engine samples load between evaluations.
+const loadSampleInterval = 5 * time.Second
+
+// DynamicConfig scales market-derived prices by time of day and by load.
+type DynamicConfig struct {
+	Enabled     bool              `yaml:"enabled"`
+	DryRun      bool              `yaml:"dry_run"`      // log the prices that would be set instead of setting them
+	Interval    time.Duration     `yaml:"interval"`     // how often the factor is re-evaluated
+	MinInterval time.Duration     `yaml:"min_interval"` // minimum time between price changes
+	MinChange   float64           `yaml:"min_change"`   // smallest relative factor change worth pushing
+	Timezone    string            `yaml:"timezone"`     // for schedule windows; empty = local time
+	Schedule    []ScheduleWindow  `yaml:"schedule"`
+	Utilization []UtilizationBand `yaml:"utilization"`
+}
+
+// ScheduleWindow multiplies prices while the cron expression matches the current minute.
+type ScheduleWindow struct {
+	Name       string  `yaml:"name"`
+	Cron       string  `yaml:"cron"` // e.g. "* 18-22 * * mon-fri"
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// UtilizationBand multiplies prices while load is in [MinLoad, MaxLoad).
+// Load is running plus queued requests over server.admission.max_concurrent:
+// 0 is idle, 1 is every slot busy and above 1 requests are queueing.
+type UtilizationBand struct {
+	MinLoad    float64 `yaml:"min_load"`
+	MaxLoad    float64 `yaml:"max_load"` // 0 = no upper bound
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// DefaultDynamic returns the rate limits used unless the config sets its own.
+func DefaultDynamic() DynamicConfig {
+	return DynamicConfig{
+		Interval:    time.Minute,
+		MinInterval: 15 * time.Minute,
+		MinChange:   0.05,
+	}
+}
+
+// Validate rejects malformed windows and bands.
+func (c DynamicConfig) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	if c.MinInterval < 0 || c.MinChange < 0 {
+		return fmt.Errorf("min_interval and min_change must not be negative")
+	}
+	if _, err := c.location(); err != nil {
+		return err
+	}
+	for i, w := range c.Schedule {
+		if _, err := parseCron(w.Cron); err != nil {
+			return fmt.Errorf("schedule %d: %w", i+1, err)
+		}
+		if w.Multiplier <= 0 {
+			return fmt.Errorf("schedule %d: multiplier must be positive", i+1)
+		}
+	}
+	for i, b := range c.Utilization {
+		if b.Multiplier <= 0 {
+			return fmt.Errorf("utilization band %d: multiplier must be positive", i+1)
+		}
+		if b.MinLoad < 0 || (b.MaxLoad != 0 && b.MaxLoad <= b.MinLoad) {
+			return fmt.Errorf("utilization band %d: need 0 <= min_load < max_load", i+1)
+		}
+	}
+	return nil
+}
+
+func (c DynamicConfig) location() (*time.Location, error) {
+	if c.Timezone == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone %q: %w", c.Timezone, err)
+	}
+	return loc, nil
+}
+
+// Engine moves the reconciler's price factor with the schedule and load.
+// The first matching schedule window and the first matching utilization band
+// each contribute a multiplier; the factor is their product. Changes smaller
+// than MinChange or sooner than MinInterval after the last one are held back.
+type Engine struct {
+	cfg     DynamicConfig
+	windows []cronSpec
+	loc     *time.Location
+	rc      *Reconciler
+	load    func() float64
+
+	mu          sync.Mutex
+	loadSum     float64
+	loadSamples int
+	factor      float64
+	changed     time.Time
+}
+
+// NewEngine creates an engine driving rc. Until SetLoad is called, utilization
+// bands see a load of 0. Unless the config is a dry run, the reconciler starts
+// keeping registered prices in line with the factor.
+func NewEngine(cfg DynamicConfig, rc *Reconciler) (*Engine, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	loc, err := cfg.location()
+	if err != nil {
+		return nil, err
+	}
+	windows := make([]cronSpec, len(cfg.Schedule))
+	for i, w := range cfg.Schedule {
+		windows[i], _ = parseCron(w.Cron)
+	}
+	if !cfg.DryRun {
+		rc.managePrices()
+	}
+	return &Engine{cfg: cfg, windows: windows, loc: loc, rc: rc, load: func() float64 { return 0 }, factor: 1}, nil
+}
+
+// SetLoad sets the load source for utilization bands. Call before Start.
+func (e *Engine) SetLoad(load func() float64) {
+	e.load = load
+}
+
+// Start samples load and re-evaluates the factor until ctx is done.
+func (e *Engine) Start(ctx context.Context) {
+	go func() {
+		sample := time.NewTicker(loadSampleInterval)
+		defer sample.Stop()
+		evaluate := time.NewTicker(e.cfg.Interval)
+		defer evaluate.Stop()
+
+		e.evaluate(ctx, time.Now(), e.load())
+		for {
+			select {
+			case <-sample.C:
+				e.mu.Lock()
+				e.loadSum += e.load()
+				e.loadSamples++
+				e.mu.Unlock()
+			case now := <-evaluate.C:
+				e.mu.Lock()
+				load := e.load()
+				if e.loadSamples > 0 {
+					load = e.loadSum / float64(e.loadSamples)
+				}
+				e.loadSum, e.loadSamples = 0, 0
+				e.mu.Unlock()
+				e.evaluate(ctx, now, load)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// Factor returns the price factor currently applied (or, in a dry run, the one that would be).
+func (e *Engine) Factor() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.factor
+}
+
+// EngineState is the applied factor and when it last changed, saved across
+// restarts so the rate limit holds through them.
+type EngineState struct {
+	Factor    float64   `json:"factor"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// State returns the engine's factor and last change, for saving.
+func (e *Engine) State() EngineState {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return EngineState{Factor: e.factor, ChangedAt: e.changed}
+}
+
+// RestoreState resumes from a previous run's factor. Call before Start.
+func (e *Engine) RestoreState(st EngineState) {
+	if st.Factor <= 0 {
+		return
+	}
+	e.mu.Lock()
+	e.factor, e.changed = st.Factor, st.ChangedAt
+	e.mu.Unlock()
+	if !e.cfg.DryRun {
+		e.rc.SetFactor(st.Factor)
+	}
+}
+
+// Target returns the factor for now and load, and which windows and bands set it.
+func (e *Engine) Target(now time.Time, load float64) (factor float64, reasons []string) {
+	factor = 1
+	local := now.In(e.loc)
+	for i, w := range e.cfg.Schedule {
+		if e.windows[i].matches(local) {
+			factor *= w.Multiplier
+			name := w.Name
+			if name == "" {
+				name = w.Cron
+			}
+			reasons = append(reasons, "schedule "+name)
+			break
+		}
+	}
+	for _, b := range e.cfg.Utilization {
+		if load >= b.MinLoad && (b.MaxLoad == 0 || load < b.MaxLoad) {
+			factor *= b.Multiplier
+			reasons = append(reasons, fmt.Sprintf("load %.2f", load))
+			break
+		}
+	}
+	return factor, reasons
+}
+
+func (e *Engine) evaluate(ctx context.Context, now time.Time, load float64) {
+	target, reasons := e.Target(now, load)
+
+	e.mu.Lock()
+	current, changed := e.factor, e.changed
+	if math.Abs(target-current) < e.cfg.MinChange*current || target == current {
+		e.mu.Unlock()
+		return
+	}
+	if !changed.IsZero() && now.Sub(changed) < e.cfg.MinInterval {
+		e.mu.Unlock()
+		logger.Debug("Dynamic price change held back by rate limit",
+			zap.Float64("factor", current),
+			zap.Float64("target", target),
+			zap.Duration("wait", e.cfg.MinInterval-now.Sub(changed)))
+		return
+	}
+	e.factor, e.changed = target, now
+	e.mu.Unlock()
+
+	if e.cfg.DryRun {
+		for _, change := range e.rc.Preview(target) {
+			logger.Info("Dynamic pricing dry run: would change model price",
+				zap.String("model", change.Model),
+				zap.Float64("factor", target),
+				zap.Strings("reasons", reasons),
+				zap.Float64("input_price", change.Prices.Input),
+				zap.Float64("output_price", change.Prices.Output))
+		}
+		return
+	}
+
+	logger.Info("Dynamic price factor changed",
+		zap.Float64("from", current),
+		zap.Float64("to", target),
+		zap.Strings("reasons", reasons))
+	e.rc.SetFactor(target)
+	if _, err := e.rc.Reprice(ctx); err != nil {
+		logger.Error("Failed to apply dynamic prices", zap.Error(err))
+	}
+}
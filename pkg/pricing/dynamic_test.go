@@ -0,0 +1,137 @@
+package pricing
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
+)
+
+func TestParseCron(t *testing.T) {
+	// 2026-03-06 is a Friday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2026, time.March, day, hour, minute, 0, 0, time.UTC)
+	}
+	cases := []struct {
+		expr string
+		t    time.Time
+		want bool
+	}{
+		{"* 18-22 * * mon-fri", at(6, 19, 30), true},
+		{"* 18-22 * * mon-fri", at(7, 19, 30), false}, // Saturday
+		{"* 18-22 * * mon-fri", at(6, 23, 0), false},
+		{"*/15 * * * *", at(6, 3, 45), true},
+		{"*/15 * * * *", at(6, 3, 46), false},
+		{"0 0-6,23 * * *", at(6, 23, 0), true},
+		{"* * 1 * 7", at(1, 12, 0), true},  // Sunday the 1st: either day field may match
+		{"* * 1 * 7", at(6, 12, 0), false}, // neither
+		{"* * * jan,dec *", at(6, 12, 0), false},
+	}
+	for _, c := range cases {
+		spec, err := parseCron(c.expr)
+		if err != nil {
+			t.Fatalf("%q: %v", c.expr, err)
+		}
+		if got := spec.matches(c.t); got != c.want {
+			t.Errorf("%q at %s = %v, want %v", c.expr, c.t.Format(time.RFC1123), got, c.want)
+		}
+	}
+
+	for _, bad := range []string{"* * * *", "60 * * * *", "* 22-18 * * *", "* * * * funday", "*/0 * * * *"} {
+		if _, err := parseCron(bad); err == nil {
+			t.Errorf("%q: expected an error", bad)
+		}
+	}
+}
+
+func dynamicConfig() DynamicConfig {
+	cfg := DefaultDynamic()
+	cfg.Enabled = true
+	cfg.Timezone = "UTC"
+	cfg.Schedule = []ScheduleWindow{
+		{Name: "peak", Cron: "* 18-22 * * *", Multiplier: 1.2},
+		{Name: "night", Cron: "* 0-6 * * *", Multiplier: 0.8},
+	}
+	cfg.Utilization = []UtilizationBand{
+		{MinLoad: 1, Multiplier: 1.25},
+		{MaxLoad: 0.05, Multiplier: 0.9},
+	}
+	return cfg
+}
+
+func TestEngineTarget(t *testing.T) {
+	e, err := NewEngine(dynamicConfig(), NewReconciler(NewClient("http://unused", "k"), "vllm", Policy{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	evening := time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC)
+	noon := time.Date(2026, 3, 6, 12, 0, 0, 0, time.UTC)
+	night := time.Date(2026, 3, 6, 3, 0, 0, 0, time.UTC)
+
+	cases := []struct {
+		now  time.Time
+		load float64
+		want float64
+	}{
+		{evening, 1.5, 1.2 * 1.25}, // peak hours with a queue
+		{evening, 0.5, 1.2},
+		{noon, 0.5, 1},
+		{night, 0, 0.8 * 0.9}, // idle overnight
+	}
+	for _, c := range cases {
+		if got, reasons := e.Target(c.now, c.load); !almostEqual(got, c.want) {
+			t.Errorf("target at %s, load %v = %v (%v), want %v", c.now.Format("15:04"), c.load, got, reasons, c.want)
+		}
+	}
+}
+
+func TestEngineRateLimitsAndDryRun(t *testing.T) {
+	platform := &platformStub{registered: []RegisteredModel{
+		{ID: "1", ModelName: "m", ServiceType: "vllm", InputPriceTokens: 0.0000001, OutputPriceTokens: 0.0000002},
+	}}
+	ts := httptest.NewServer(platform)
+	defer ts.Close()
+
+	ctx := context.Background()
+	models := []llm.Model{{ID: "m", VerificationStatus: string(verify.StatusVerified)}}
+	evening := time.Date(2026, 3, 6, 19, 0, 0, 0, time.UTC)
+
+	// A dry run previews the change but leaves the platform alone.
+	cfg := dynamicConfig()
+	cfg.DryRun = true
+	rc := NewReconciler(NewClient(ts.URL, "k"), "vllm", Policy{})
+	if _, err := rc.Reconcile(ctx, models); err != nil {
+		t.Fatal(err)
+	}
+	e, _ := NewEngine(cfg, rc)
+	e.evaluate(ctx, evening, 0.5)
+	if len(platform.calls) != 0 {
+		t.Fatalf("dry run called the platform: %v", platform.calls)
+	}
+	if preview := rc.Preview(1.2); len(preview) != 1 || !almostEqual(preview[0].Prices.Input, 0.00000012) {
+		t.Fatalf("preview = %+v", preview)
+	}
+
+	// For real: the first change goes out, the next is held back by min_interval.
+	rc = NewReconciler(NewClient(ts.URL, "k"), "vllm", Policy{})
+	if _, err := rc.Reconcile(ctx, models); err != nil {
+		t.Fatal(err)
+	}
+	e, _ = NewEngine(dynamicConfig(), rc)
+	e.evaluate(ctx, evening, 0.5)
+	if want := []string{"update 1 m"}; !reflect.DeepEqual(platform.calls, want) {
+		t.Fatalf("calls = %v, want %v", platform.calls, want)
+	}
+	e.evaluate(ctx, evening.Add(time.Minute), 1.5)
+	if e.Factor() != 1.2 || len(platform.calls) != 1 {
+		t.Fatalf("factor %v, calls %v: change within min_interval was not held back", e.Factor(), platform.calls)
+	}
+	e.evaluate(ctx, evening.Add(20*time.Minute), 1.5)
+	if !almostEqual(e.Factor(), 1.5) || len(platform.calls) != 2 {
+		t.Fatalf("factor %v, calls %v: change after min_interval was not applied", e.Factor(), platform.calls)
+	}
+}
@@ -0,0 +1,123 @@
+package pricing
+
+import (
+	"fmt"
+	"math"
+)
+
+// DefaultModelPrice is used when the platform returns neither a price for the
+// model nor a "default" entry.
+var DefaultModelPrice = ModelPrice{
+	ModelName:      "default",
+	AvgInputPrice:  0.0002,
+	AvgOutputPrice: 0.0003,
+}
+
+// PriceLimit bounds input and output prices, in USD per 1M tokens. Zero means no limit.
+type PriceLimit struct {
+	Input  float64 `yaml:"input_per_1m"`
+	Output float64 `yaml:"output_per_1m"`
+}
+
+// ModelPolicy overrides the policy for one model. Fixed prices (USD per 1M
+// tokens) replace the market average and are not clamped by floor or ceiling.
+type ModelPolicy struct {
+	Input  float64  `yaml:"input_per_1m"`  // 0 = from the market average
+	Output float64  `yaml:"output_per_1m"` // 0 = from the market average
+	Markup *float64 `yaml:"markup"`        // overrides the global markup
+}
+
+// Policy decides the prices models are registered at. Market-derived prices
+// are the platform average times (1 + markup), then clamped to floor and
+// ceiling. An empty policy registers at the market average, as before.
+type Policy struct {
+	Markup  float64                `yaml:"markup"` // 0.1 = 10% above market, -0.05 = 5% discount
+	Floor   PriceLimit             `yaml:"floor"`
+	Ceiling PriceLimit             `yaml:"ceiling"`
+	Models  map[string]ModelPolicy `yaml:"models"`
+}
+
+// IsZero reports whether no policy is configured.
+func (p Policy) IsZero() bool {
+	return p.Markup == 0 && p.Floor == (PriceLimit{}) && p.Ceiling == (PriceLimit{}) && len(p.Models) == 0
+}
+
+// Validate rejects policies that could produce zero, negative or contradictory prices.
+func (p Policy) Validate() error {
+	if p.Markup <= -1 {
+		return fmt.Errorf("markup %v would make prices zero or negative", p.Markup)
+	}
+	if err := checkLimits(p.Floor, p.Ceiling); err != nil {
+		return err
+	}
+	for model, mp := range p.Models {
+		if mp.Input < 0 || mp.Output < 0 {
+			return fmt.Errorf("model %s: prices must not be negative", model)
+		}
+		if mp.Markup != nil && *mp.Markup <= -1 {
+			return fmt.Errorf("model %s: markup %v would make prices zero or negative", model, *mp.Markup)
+		}
+	}
+	return nil
+}
+
+func checkLimits(floor, ceiling PriceLimit) error {
+	if floor.Input < 0 || floor.Output < 0 || ceiling.Input < 0 || ceiling.Output < 0 {
+		return fmt.Errorf("floor and ceiling must not be negative")
+	}
+	if ceiling.Input > 0 && floor.Input > ceiling.Input {
+		return fmt.Errorf("input floor %v is above the ceiling %v", floor.Input, ceiling.Input)
+	}
+	if ceiling.Output > 0 && floor.Output > ceiling.Output {
+		return fmt.Errorf("output floor %v is above the ceiling %v", floor.Output, ceiling.Output)
+	}
+	return nil
+}
+
+// Price returns the USD per-token input and output prices to register model
+// at, given the platform's prices (which may be nil).
+func (p Policy) Price(model string, prices *GetPricesResponse) (input, output float64) {
+	return p.PriceAt(model, prices, 1)
+}
+
+// PriceAt is Price with market-derived prices scaled by factor before they are
+// clamped, as dynamic pricing does. Fixed per-model prices are not scaled.
+func (p Policy) PriceAt(model string, prices *GetPricesResponse, factor float64) (input, output float64) {
+	mp := p.Models[model]
+	markup := p.Markup
+	if mp.Markup != nil {
+		markup = *mp.Markup
+	}
+
+	market := prices.MarketPrice(model)
+	input = clamp(market.AvgInputPrice*(1+markup)*factor, p.Floor.Input/1e6, p.Ceiling.Input/1e6)
+	output = clamp(market.AvgOutputPrice*(1+markup)*factor, p.Floor.Output/1e6, p.Ceiling.Output/1e6)
+
+	if mp.Input > 0 {
+		input = mp.Input / 1e6
+	}
+	if mp.Output > 0 {
+		output = mp.Output / 1e6
+	}
+	return input, output
+}
+
+// MarketPrice returns the platform average for model, the "default" entry
+// when the platform has none for it, or DefaultModelPrice.
+func (r *GetPricesResponse) MarketPrice(model string) ModelPrice {
+	if price, _, ok := r.lookup(model); ok {
+		return price
+	}
+	return DefaultModelPrice
+}
+
+// clamp bounds v to [floor, ceiling]; zero limits are ignored.
+func clamp(v, floor, ceiling float64) float64 {
+	if floor > 0 {
+		v = math.Max(v, floor)
+	}
+	if ceiling > 0 {
+		v = math.Min(v, ceiling)
+	}
+	return v
+}
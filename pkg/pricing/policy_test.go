@@ -0,0 +1,77 @@
+package pricing
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const policyYAML = `
+markup: 0.1
+floor:
+  input_per_1m: 0.05
+ceiling:
+  output_per_1m: 0.5
+models:
+  llama3:
+    input_per_1m: 0.2
+    output_per_1m: 0.8
+  mistral:
+    markup: -0.2
+`
+
+func TestPolicyPrice(t *testing.T) {
+	var policy Policy
+	if err := yaml.Unmarshal([]byte(policyYAML), &policy); err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	prices := &GetPricesResponse{ModelPrices: []ModelPrice{
+		{ModelName: "qwen", AvgInputPrice: 0.0000001, AvgOutputPrice: 0.0000004},
+		{ModelName: "mistral", AvgInputPrice: 0.0000001, AvgOutputPrice: 0.0000002},
+		{ModelName: "default", AvgInputPrice: 0.00000001, AvgOutputPrice: 0.000001},
+	}}
+
+	cases := []struct {
+		model      string
+		in, out    float64
+		prices     *GetPricesResponse
+		reasonText string
+	}{
+		{"qwen", 0.00000011, 0.00000044, prices, "market average plus 10%"},
+		{"mistral", 0.00000008, 0.00000016, prices, "per-model 20% discount"},
+		{"phi", 0.00000005, 0.0000005, prices, "default entry clamped to floor and ceiling"},
+		{"llama3", 0.0000002, 0.0000008, prices, "fixed prices ignore the ceiling"},
+		{"qwen", DefaultModelPrice.AvgInputPrice * 1.1, 0.0000005, nil, "no platform prices"},
+	}
+	for _, c := range cases {
+		in, out := policy.Price(c.model, c.prices)
+		if !almostEqual(in, c.in) || !almostEqual(out, c.out) {
+			t.Errorf("%s (%s) = %g / %g, want %g / %g", c.model, c.reasonText, in, out, c.in, c.out)
+		}
+	}
+}
+
+func TestEmptyPolicyUsesMarketAverage(t *testing.T) {
+	prices := &GetPricesResponse{ModelPrices: []ModelPrice{{ModelName: "m", AvgInputPrice: 0.1, AvgOutputPrice: 0.2}}}
+	if in, out := (Policy{}).Price("m", prices); in != 0.1 || out != 0.2 {
+		t.Fatalf("price = %v / %v, want the market average", in, out)
+	}
+}
+
+func TestPolicyValidate(t *testing.T) {
+	minusOne := -1.0
+	invalid := map[string]Policy{
+		"markup -100%":       {Markup: -1},
+		"floor over ceiling": {Floor: PriceLimit{Output: 2}, Ceiling: PriceLimit{Output: 1}},
+		"negative fixed":     {Models: map[string]ModelPolicy{"m": {Input: -1}}},
+		"model markup":       {Models: map[string]ModelPolicy{"m": {Markup: &minusOne}}},
+	}
+	for name, p := range invalid {
+		if err := p.Validate(); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
@@ -0,0 +1,426 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
+	"go.uber.org/zap"
+)
+
+// priceTolerance is the relative price difference below which a registered
+// model is left alone, so market averages drifting by fractions of a cent
+// do not cause an update every cycle.
+const priceTolerance = 0.001
+
+// Prices are USD per-token input and output prices.
+type Prices struct {
+	Input  float64
+	Output float64
+}
+
+// ChangeKind is what a Change does to a platform registration.
+type ChangeKind string
+
+const (
+	ChangeAdd    ChangeKind = "add"
+	ChangeUpdate ChangeKind = "update"
+	ChangeRemove ChangeKind = "remove"
+)
+
+// Change is one registration the reconciler makes, updates or removes.
+type Change struct {
+	Kind   ChangeKind
+	Model  string
+	ID     string // platform ID; empty for adds
+	Prices Prices
+}
+
+// Plan diffs the desired model prices against the platform's registrations
+// and returns the changes, sorted by model. Prices of registered models are
+// only updated when reprice is set. Registrations without an ID (known only
+// from a failed add) cannot be updated or removed.
+func Plan(desired map[string]Prices, registered []RegisteredModel, reprice bool) []Change {
+	var changes []Change
+	seen := make(map[string]bool, len(registered))
+	for _, reg := range registered {
+		seen[reg.ModelName] = true
+		want, ok := desired[reg.ModelName]
+		switch {
+		case reg.ID == "":
+			// Cannot be changed without its platform ID
+		case !ok:
+			changes = append(changes, Change{Kind: ChangeRemove, Model: reg.ModelName, ID: reg.ID})
+		case reprice && (!closeEnough(reg.InputPriceTokens, want.Input) || !closeEnough(reg.OutputPriceTokens, want.Output)):
+			changes = append(changes, Change{Kind: ChangeUpdate, Model: reg.ModelName, ID: reg.ID, Prices: want})
+		}
+	}
+	for model, want := range desired {
+		if !seen[model] {
+			changes = append(changes, Change{Kind: ChangeAdd, Model: model, Prices: want})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Model < changes[j].Model })
+	return changes
+}
+
+func closeEnough(a, b float64) bool {
+	return math.Abs(a-b) <= priceTolerance*math.Max(math.Abs(a), math.Abs(b))
+}
+
+// scaledPrice is a registration's price at a factor of 1 and the dynamic
+// pricing factor it is registered at.
+type scaledPrice struct {
+	base   Prices
+	factor float64
+}
+
+// Reconciler keeps the platform's model registrations in line with the
+// verified local models and the pricing policy: new models are added and
+// models that are gone, failed or not approved removed. A model whose
+// verification has no outcome yet (an error, or a pending result) keeps its
+// registration. Registered prices are only
+// updated when a pricing policy is configured, so prices edited in the
+// dashboard survive on nodes that do not manage them in config.
+type Reconciler struct {
+	client      *Client
+	serviceType string
+	policy      Policy
+
+	run sync.Mutex // one reconcile at a time: health cycles and dynamic pricing share the reconciler
+
+	mu      sync.Mutex
+	reprice bool
+	factor  float64                // dynamic pricing multiplier on market-derived prices
+	applied float64                // factor of the last reconcile; 0 before the first
+	bases   map[string]scaledPrice // without a policy: registered prices the factor scales
+	names   []string               // verified models as of the last reconcile
+	held    map[string]bool        // unsettled models as of the last reconcile; never removed
+	market  *GetPricesResponse     // platform prices as of the last reconcile
+	known   []RegisteredModel      // last listed or registered state, used when listing fails
+}
+
+// NewReconciler creates a reconciler for this provider's service type.
+func NewReconciler(client *Client, serviceType string, policy Policy) *Reconciler {
+	// Normalize service type to match API expectations
+	normalized := strings.ToLower(serviceType)
+	if normalized != "vllm" && normalized != "ollama" {
+		logger.Warn("Invalid service type, defaulting to vllm",
+			zap.String("original_service_type", serviceType))
+		normalized = "vllm"
+	}
+	return &Reconciler{client: client, serviceType: normalized, policy: policy, reprice: !policy.IsZero(), factor: 1, bases: make(map[string]scaledPrice)}
+}
+
+// SetFactor sets the multiplier applied to market-derived prices from the next reconcile on.
+func (r *Reconciler) SetFactor(factor float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factor = factor
+}
+
+// managePrices makes the reconciler update registered prices even without a
+// policy, because dynamic pricing owns them. Without a policy the factor scales
+// each model's registered price, so prices set in the dashboard are kept.
+func (r *Reconciler) managePrices() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reprice = true
+}
+
+// Registrations returns the last known platform registrations, for saving across restarts.
+func (r *Reconciler) Registrations() []RegisteredModel {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]RegisteredModel(nil), r.known...)
+}
+
+// RegisteredPrices returns the per-token prices model was last known to be
+// registered at. Registrations of other service types are not kept, so a
+// model served by another backend on the same provider is not found.
+func (r *Reconciler) RegisteredPrices(model string) (Prices, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, reg := range r.known {
+		if reg.ModelName == model {
+			return Prices{Input: reg.InputPriceTokens, Output: reg.OutputPriceTokens}, true
+		}
+	}
+	return Prices{}, false
+}
+
+// RestoreRegistrations seeds the known registrations from a previous run, so
+// a platform listing failure at startup does not re-register every model.
+func (r *Reconciler) RestoreRegistrations(known []RegisteredModel) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.known = known
+}
+
+// ReconcileLocal lists and verifies the backend's models, then reconciles them.
+// Used at startup, before the first health report has verified anything.
+func (r *Reconciler) ReconcileLocal(ctx context.Context, llmClient llm.Client, verifier *verify.Verifier) ([]Change, error) {
+	models, err := llmClient.ListModels(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list local models: %w", err)
+	}
+	return r.Reconcile(ctx, verifier.ApplyToModels(ctx, llmClient, models.Models))
+}
+
+// Reconcile registers the verified models among models at policy prices and
+// removes this service type's registrations for anything else. It returns
+// the changes that were applied; failed changes are logged and retried on
+// the next call.
+func (r *Reconciler) Reconcile(ctx context.Context, models []llm.Model) ([]Change, error) {
+	r.run.Lock()
+	defer r.run.Unlock()
+
+	var names []string
+	held := make(map[string]bool)
+	for _, model := range models {
+		if !verify.IsInferenceAllowed(model.VerificationStatus) {
+			logger.Debug("Not registering unverified model",
+				zap.String("model", model.ID),
+				zap.String("verification_status", model.VerificationStatus))
+			if !verify.IsSettled(model.VerificationStatus) {
+				held[model.ID] = true
+			}
+			continue
+		}
+		names = append(names, model.ID)
+	}
+
+	var market *GetPricesResponse
+	if len(names) > 0 {
+		prices, err := r.client.GetModelPrices(ctx, names)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get model prices: %w", err)
+		}
+		market = prices
+	}
+
+	r.mu.Lock()
+	r.names, r.market, r.held = names, market, held
+	r.mu.Unlock()
+	return r.reconcile(ctx)
+}
+
+// Reprice reconciles the models and platform prices of the last reconcile at
+// the current factor, without asking the backend or the platform for prices.
+func (r *Reconciler) Reprice(ctx context.Context) ([]Change, error) {
+	r.run.Lock()
+	defer r.run.Unlock()
+
+	r.mu.Lock()
+	empty := len(r.names) == 0
+	r.mu.Unlock()
+	if empty {
+		return nil, nil
+	}
+	return r.reconcile(ctx)
+}
+
+// Preview returns the price updates a reconcile at factor would make against
+// the last known registrations, without calling the platform.
+func (r *Reconciler) Preview(factor float64) []Change {
+	r.mu.Lock()
+	known := r.known
+	desired := r.desired(r.names, r.market, factor, known)
+	r.mu.Unlock()
+
+	var updates []Change
+	for _, change := range Plan(desired, known, true) {
+		if change.Kind == ChangeUpdate && !leaveAlone(change, known, nil) {
+			updates = append(updates, change)
+		}
+	}
+	return updates
+}
+
+// desired prices the names at factor: from the policy, or without one by
+// scaling what each model is registered at. Models not registered yet get the
+// market average. Callers hold r.mu.
+func (r *Reconciler) desired(names []string, market *GetPricesResponse, factor float64, registered []RegisteredModel) map[string]Prices {
+	desired := make(map[string]Prices, len(names))
+	for _, name := range names {
+		in, out := r.policy.PriceAt(name, market, factor)
+		desired[name] = Prices{Input: in, Output: out}
+	}
+	if !r.policy.IsZero() {
+		return desired
+	}
+	for _, reg := range registered {
+		if _, ok := desired[reg.ModelName]; ok && reg.ID != "" {
+			base := r.registeredBase(reg).base
+			desired[reg.ModelName] = Prices{Input: base.Input * factor, Output: base.Output * factor}
+		}
+	}
+	return desired
+}
+
+// registeredBase returns reg's unscaled price. A registration the reconciler
+// has not priced, or whose price was edited in the dashboard since, is taken
+// to be at the factor of the last reconcile. Callers hold r.mu.
+func (r *Reconciler) registeredBase(reg RegisteredModel) scaledPrice {
+	s, ok := r.bases[reg.ModelName]
+	if ok && closeEnough(reg.InputPriceTokens, s.base.Input*s.factor) && closeEnough(reg.OutputPriceTokens, s.base.Output*s.factor) {
+		return s
+	}
+	if !ok {
+		s.factor = r.applied
+		if s.factor == 0 {
+			s.factor = r.factor
+		}
+	}
+	s.base = Prices{Input: reg.InputPriceTokens / s.factor, Output: reg.OutputPriceTokens / s.factor}
+	return s
+}
+
+// reconcile diffs the cached models and prices against the platform and
+// applies the changes. The caller holds r.run.
+func (r *Reconciler) reconcile(ctx context.Context) ([]Change, error) {
+	registered, err := r.client.ListModels(ctx)
+	if err != nil {
+		logger.Warn("Failed to list registered models; reconciling against the last known state", zap.Error(err))
+		r.mu.Lock()
+		registered = append([]RegisteredModel(nil), r.known...)
+		r.mu.Unlock()
+	}
+	registered = r.ownServiceType(registered)
+
+	r.mu.Lock()
+	factor, reprice, held := r.factor, r.reprice, r.held
+	if r.policy.IsZero() {
+		for _, reg := range registered {
+			if reg.ID != "" {
+				r.bases[reg.ModelName] = r.registeredBase(reg)
+			}
+		}
+	}
+	desired := r.desired(r.names, r.market, factor, registered)
+	r.mu.Unlock()
+
+	var applied []Change
+	for _, change := range Plan(desired, registered, reprice) {
+		if leaveAlone(change, registered, held) {
+			continue
+		}
+		if err := r.apply(ctx, change); err != nil {
+			if change.Kind == ChangeAdd && errors.Is(err, ErrModelAlreadyExists) {
+				// Registered, but not in the list we had; the next listing fills in its ID.
+				registered = append(registered, RegisteredModel{ModelName: change.Model, ServiceType: r.serviceType})
+				continue
+			}
+			logger.Error("Failed to reconcile model registration",
+				zap.String("model", change.Model),
+				zap.String("change", string(change.Kind)),
+				zap.Error(err))
+			continue
+		}
+		registered = applyToKnown(registered, change, r.serviceType)
+		r.recordBase(change, factor)
+		applied = append(applied, change)
+		logger.Info("Reconciled model registration",
+			zap.String("model", change.Model),
+			zap.String("change", string(change.Kind)),
+			zap.Float64("input_price", change.Prices.Input),
+			zap.Float64("output_price", change.Prices.Output))
+	}
+
+	r.mu.Lock()
+	r.known = registered
+	r.applied = factor
+	r.mu.Unlock()
+	return applied, nil
+}
+
+// recordBase remembers the unscaled price of a change applied at factor.
+func (r *Reconciler) recordBase(change Change, factor float64) {
+	if !r.policy.IsZero() {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if change.Kind == ChangeRemove {
+		delete(r.bases, change.Model)
+		return
+	}
+	r.bases[change.Model] = scaledPrice{
+		base:   Prices{Input: change.Prices.Input / factor, Output: change.Prices.Output / factor},
+		factor: factor,
+	}
+}
+
+func (r *Reconciler) apply(ctx context.Context, change Change) error {
+	switch change.Kind {
+	case ChangeAdd:
+		return r.client.RegisterModel(ctx, change.Model, r.serviceType, change.Prices.Input, change.Prices.Output)
+	case ChangeUpdate:
+		return r.client.UpdateModel(ctx, change.ID, change.Model, r.serviceType, change.Prices.Input, change.Prices.Output)
+	case ChangeRemove:
+		return r.client.DeleteModel(ctx, change.ID)
+	}
+	return fmt.Errorf("unknown change %q", change.Kind)
+}
+
+// ownServiceType keeps the registrations for this reconciler's service type;
+// the same provider may run other backends that this node must not touch.
+// Registrations without a service type are kept so their models are not
+// added again, but leaveAlone stops them from being changed.
+func (r *Reconciler) ownServiceType(registered []RegisteredModel) []RegisteredModel {
+	own := registered[:0:0]
+	for _, reg := range registered {
+		if reg.ServiceType == "" || strings.EqualFold(reg.ServiceType, r.serviceType) {
+			own = append(own, reg)
+		}
+	}
+	return own
+}
+
+// leaveAlone reports whether a planned change must be skipped: registrations
+// without a service type may belong to another backend, and held models keep
+// their registration until verification has an outcome.
+func leaveAlone(change Change, registered []RegisteredModel, held map[string]bool) bool {
+	if change.Kind == ChangeAdd {
+		return false
+	}
+	if change.Kind == ChangeRemove && held[change.Model] {
+		return true
+	}
+	for _, reg := range registered {
+		if reg.ID == change.ID && reg.ServiceType == "" {
+			return true
+		}
+	}
+	return false
+}
+
+// applyToKnown records a successful change in the known registrations.
+func applyToKnown(known []RegisteredModel, change Change, serviceType string) []RegisteredModel {
+	out := known[:0:0]
+	for _, reg := range known {
+		if reg.ModelName == change.Model {
+			if change.Kind == ChangeRemove {
+				continue
+			}
+			reg.InputPriceTokens, reg.OutputPriceTokens = change.Prices.Input, change.Prices.Output
+		}
+		out = append(out, reg)
+	}
+	if change.Kind == ChangeAdd {
+		out = append(out, RegisteredModel{
+			ModelName:         change.Model,
+			ServiceType:       serviceType,
+			InputPriceTokens:  change.Prices.Input,
+			OutputPriceTokens: change.Prices.Output,
+		})
+	}
+	return out
+}
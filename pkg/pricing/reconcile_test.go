@@ -0,0 +1,215 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
+)
+
+func TestPlan(t *testing.T) {
+	desired := map[string]Prices{
+		"keep":    {Input: 0.0000001, Output: 0.0000002},
+		"reprice": {Input: 0.0000003, Output: 0.0000004},
+		"new":     {Input: 0.0000001, Output: 0.0000001},
+	}
+	registered := []RegisteredModel{
+		{ID: "1", ModelName: "keep", InputPriceTokens: 0.00000010001, OutputPriceTokens: 0.0000002},
+		{ID: "2", ModelName: "reprice", InputPriceTokens: 0.0000001, OutputPriceTokens: 0.0000004},
+		{ID: "3", ModelName: "deleted"},
+		{ModelName: "no-id"},
+	}
+
+	got := Plan(desired, registered, true)
+	want := []Change{
+		{Kind: ChangeRemove, Model: "deleted", ID: "3"},
+		{Kind: ChangeAdd, Model: "new", Prices: desired["new"]},
+		{Kind: ChangeUpdate, Model: "reprice", ID: "2", Prices: desired["reprice"]},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("plan = %+v\nwant %+v", got, want)
+	}
+
+	// Without a pricing policy, dashboard prices are left alone.
+	for _, change := range Plan(desired, registered, false) {
+		if change.Kind == ChangeUpdate {
+			t.Fatalf("unexpected update without repricing: %+v", change)
+		}
+	}
+}
+
+// platformStub fakes the pricing and provider-model endpoints and records calls.
+type platformStub struct {
+	mu         sync.Mutex
+	registered []RegisteredModel
+	listFails  bool
+	calls      []string
+}
+
+func (p *platformStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	switch {
+	case r.URL.Path == "/api/model-pricing/get-prices":
+		json.NewEncoder(w).Encode(GetPricesResponse{ModelPrices: []ModelPrice{
+			{ModelName: "default", AvgInputPrice: 0.0000001, AvgOutputPrice: 0.0000002},
+		}})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/provider/models":
+		if p.listFails {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(ListModelsResponse{Models: p.registered})
+	case r.Method == http.MethodPost && r.URL.Path == "/api/provider/models":
+		var req RegisterModelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		p.calls = append(p.calls, "add "+req.ModelName)
+	case r.Method == http.MethodPut:
+		var req RegisterModelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		id := strings.TrimPrefix(r.URL.Path, "/api/provider/models/")
+		p.calls = append(p.calls, "update "+id+" "+req.ModelName)
+		for i := range p.registered {
+			if p.registered[i].ID == id {
+				p.registered[i].InputPriceTokens, p.registered[i].OutputPriceTokens = req.InputPriceTokens, req.OutputPriceTokens
+			}
+		}
+	case r.Method == http.MethodDelete:
+		p.calls = append(p.calls, "remove "+strings.TrimPrefix(r.URL.Path, "/api/provider/models/"))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestReconcilerAddsUpdatesAndRemoves(t *testing.T) {
+	platform := &platformStub{registered: []RegisteredModel{
+		{ID: "10", ModelName: "llama3", ServiceType: "ollama", InputPriceTokens: 0.0000001, OutputPriceTokens: 0.0000002},
+		{ID: "11", ModelName: "mistral", ServiceType: "ollama", InputPriceTokens: 0.0000005, OutputPriceTokens: 0.0000005},
+		{ID: "12", ModelName: "removed-from-ollama", ServiceType: "ollama"},
+		{ID: "13", ModelName: "Qwen/Qwen3-32B", ServiceType: "vllm"},
+	}}
+	ts := httptest.NewServer(platform)
+	defer ts.Close()
+
+	rc := NewReconciler(NewClient(ts.URL, "k"), "Ollama", Policy{Floor: PriceLimit{Input: 0.05}})
+	applied, err := rc.Reconcile(context.Background(), []llm.Model{
+		{ID: "llama3", VerificationStatus: string(verify.StatusVerified)},
+		{ID: "mistral", VerificationStatus: string(verify.StatusVerified)},
+		{ID: "phi3", VerificationStatus: string(verify.StatusVerified)},
+		{ID: "tampered", VerificationStatus: string(verify.StatusFailed)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// llama3 is already at the market price; the vLLM registration belongs to another backend.
+	want := []string{"update 11 mistral", "add phi3", "remove 12"}
+	if !reflect.DeepEqual(platform.calls, want) {
+		t.Fatalf("calls = %v, want %v", platform.calls, want)
+	}
+	if len(applied) != 3 {
+		t.Fatalf("applied = %+v", applied)
+	}
+	// Registered prices come from the listing and from the changes just applied
+	if prices, ok := rc.RegisteredPrices("llama3"); !ok || prices != (Prices{Input: 0.0000001, Output: 0.0000002}) {
+		t.Fatalf("llama3 registered at %+v, %v", prices, ok)
+	}
+	if prices, ok := rc.RegisteredPrices("phi3"); !ok || prices != applied[1].Prices {
+		t.Fatalf("phi3 registered at %+v, %v", prices, ok)
+	}
+	if _, ok := rc.RegisteredPrices("removed-from-ollama"); ok {
+		t.Fatal("removed model still has a registered price")
+	}
+}
+
+func TestReconcilerOnlyRemovesSettledModels(t *testing.T) {
+	platform := &platformStub{registered: []RegisteredModel{
+		{ID: "1", ModelName: "pending", ServiceType: "vllm"},
+		{ID: "2", ModelName: "failed", ServiceType: "vllm"},
+		{ID: "3", ModelName: "untyped"},
+		{ID: "4", ModelName: "gone", ServiceType: "vllm"},
+	}}
+	ts := httptest.NewServer(platform)
+	defer ts.Close()
+
+	rc := NewReconciler(NewClient(ts.URL, "k"), "vllm", Policy{})
+	_, err := rc.Reconcile(context.Background(), []llm.Model{
+		{ID: "pending", VerificationStatus: string(verify.StatusPending)},
+		{ID: "failed", VerificationStatus: string(verify.StatusFailed)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A pending result and a registration without a service type are left alone.
+	if want := []string{"remove 2", "remove 4"}; !reflect.DeepEqual(platform.calls, want) {
+		t.Fatalf("calls = %v, want %v", platform.calls, want)
+	}
+}
+
+func TestReconcilerScalesRegisteredPricesWithoutPolicy(t *testing.T) {
+	// Set in the dashboard, well above the 0.1/0.2 market average
+	platform := &platformStub{registered: []RegisteredModel{
+		{ID: "1", ModelName: "m", ServiceType: "vllm", InputPriceTokens: 0.000001, OutputPriceTokens: 0.000002},
+	}}
+	ts := httptest.NewServer(platform)
+	defer ts.Close()
+
+	rc := NewReconciler(NewClient(ts.URL, "k"), "vllm", Policy{})
+	rc.managePrices()
+	models := []llm.Model{{ID: "m", VerificationStatus: string(verify.StatusVerified)}}
+	if _, err := rc.Reconcile(context.Background(), models); err != nil {
+		t.Fatal(err)
+	}
+	if len(platform.calls) != 0 {
+		t.Fatalf("calls = %v, want the dashboard price kept", platform.calls)
+	}
+
+	price := func() Prices {
+		p, _ := rc.RegisteredPrices("m")
+		return p
+	}
+	rc.SetFactor(1.5)
+	if _, err := rc.Reprice(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if p := price(); !closeEnough(p.Input, 0.0000015) || !closeEnough(p.Output, 0.000003) {
+		t.Fatalf("prices at 1.5 = %+v", p)
+	}
+
+	// A dashboard edit at 1.5 becomes the new base
+	platform.mu.Lock()
+	platform.registered[0].InputPriceTokens = 0.000003
+	platform.mu.Unlock()
+	rc.SetFactor(1)
+	if _, err := rc.Reprice(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if p := price(); !closeEnough(p.Input, 0.000002) || !closeEnough(p.Output, 0.000002) {
+		t.Fatalf("prices back at 1 = %+v", p)
+	}
+}
+
+func TestReconcilerFallsBackToKnownStateWhenListingFails(t *testing.T) {
+	platform := &platformStub{listFails: true}
+	ts := httptest.NewServer(platform)
+	defer ts.Close()
+
+	rc := NewReconciler(NewClient(ts.URL, "k"), "vllm", Policy{})
+	models := []llm.Model{{ID: "m", VerificationStatus: string(verify.StatusVerified)}}
+	if _, err := rc.Reconcile(context.Background(), models); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rc.Reconcile(context.Background(), models); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(platform.calls, []string{"add m"}) {
+		t.Fatalf("calls = %v, want a single add", platform.calls)
+	}
+}
@@ -0,0 +1,178 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/internal/config"
+)
+
+// defaultMaxConcurrent applies when admission.max_concurrent is unset.
+const defaultMaxConcurrent = 8
+
+var (
+	// ErrQueueFull is returned when every slot is taken and the wait queue is full.
+	ErrQueueFull = errors.New("node at capacity: wait queue is full")
+	// ErrQueueTimeout is returned when a request waited longer than queue_timeout for a slot.
+	ErrQueueTimeout = errors.New("node at capacity: timed out waiting for a slot")
+)
+
+// admissionController limits concurrent inference per node and per model.
+// Requests over the limit wait in a FIFO queue; a waiter is admitted as soon as
+// both its model and the node have a free slot, so a saturated model does not
+// block requests for other models queued behind it.
+type admissionController struct {
+	cfg config.AdmissionConfig
+
+	mu       sync.Mutex
+	active   int
+	perModel map[string]int
+	queue    []*admissionWaiter
+}
+
+type admissionWaiter struct {
+	model string
+	ready chan struct{}
+}
+
+// AdmissionStatus is a point-in-time view of admission capacity.
+type AdmissionStatus struct {
+	MaxConcurrent  int
+	Active         int
+	AvailableSlots int
+	QueueDepth     int
+	MaxQueue       int
+}
+
+func newAdmissionController(cfg config.AdmissionConfig) *admissionController {
+	if cfg.MaxConcurrent <= 0 {
+		cfg.MaxConcurrent = defaultMaxConcurrent
+	}
+	return &admissionController{
+		cfg:      cfg,
+		perModel: make(map[string]int),
+	}
+}
+
+// Acquire reserves a slot for model, waiting in the queue if necessary.
+// The returned release func must be called once the request finishes.
+func (a *admissionController) Acquire(ctx context.Context, model string) (release func(), err error) {
+	a.mu.Lock()
+	// Queued waiters are only ever blocked by a limit, so a request that fits
+	// now does not jump ahead of anyone who could have run.
+	if a.canAdmit(model) {
+		a.admit(model)
+		a.mu.Unlock()
+		return a.releaser(model), nil
+	}
+	if len(a.queue) >= a.cfg.MaxQueue {
+		a.mu.Unlock()
+		return nil, ErrQueueFull
+	}
+	w := &admissionWaiter{model: model, ready: make(chan struct{})}
+	a.queue = append(a.queue, w)
+	a.mu.Unlock()
+
+	var timeout <-chan time.Time
+	if a.cfg.QueueTimeout > 0 {
+		timer := time.NewTimer(a.cfg.QueueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case <-w.ready:
+		return a.releaser(model), nil
+	case <-timeout:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if !a.removeWaiter(w) {
+		// Admitted while we were giving up; hand the slot straight back.
+		a.releaseLocked(model)
+	}
+	return nil, err
+}
+
+// Status reports current capacity for /api/busy.
+func (a *admissionController) Status() AdmissionStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return AdmissionStatus{
+		MaxConcurrent:  a.cfg.MaxConcurrent,
+		Active:         a.active,
+		AvailableSlots: max(a.cfg.MaxConcurrent-a.active, 0),
+		QueueDepth:     len(a.queue),
+		MaxQueue:       a.cfg.MaxQueue,
+	}
+}
+
+// Load returns running plus queued requests over the node limit: 0 is idle,
+// 1 is every slot busy and above 1 requests are queueing.
+func (a *admissionController) Load() float64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return float64(a.active+len(a.queue)) / float64(a.cfg.MaxConcurrent)
+}
+
+func (a *admissionController) canAdmit(model string) bool {
+	if a.active >= a.cfg.MaxConcurrent {
+		return false
+	}
+	if limit := a.cfg.ModelLimit(model); limit > 0 && a.perModel[model] >= limit {
+		return false
+	}
+	return true
+}
+
+func (a *admissionController) admit(model string) {
+	a.active++
+	a.perModel[model]++
+}
+
+func (a *admissionController) releaser(model string) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			a.mu.Lock()
+			defer a.mu.Unlock()
+			a.releaseLocked(model)
+		})
+	}
+}
+
+func (a *admissionController) releaseLocked(model string) {
+	a.active--
+	a.perModel[model]--
+	if a.perModel[model] <= 0 {
+		delete(a.perModel, model)
+	}
+
+	// Admit queued requests in order wherever their model has room.
+	remaining := a.queue[:0]
+	for _, w := range a.queue {
+		if a.canAdmit(w.model) {
+			a.admit(w.model)
+			close(w.ready)
+			continue
+		}
+		remaining = append(remaining, w)
+	}
+	a.queue = remaining
+}
+
+func (a *admissionController) removeWaiter(w *admissionWaiter) bool {
+	for i, q := range a.queue {
+		if q == w {
+			a.queue = append(a.queue[:i], a.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,106 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/internal/config"
+)
+
+func TestAdmissionNodeLimitAndQueue(t *testing.T) {
+	a := newAdmissionController(config.AdmissionConfig{MaxConcurrent: 1, MaxQueue: 1, QueueTimeout: time.Second})
+
+	release, err := a.Acquire(context.Background(), "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	admitted := make(chan error, 1)
+	go func() {
+		r, err := a.Acquire(context.Background(), "m")
+		if err == nil {
+			r()
+		}
+		admitted <- err
+	}()
+	waitForQueueDepth(t, a, 1)
+
+	if _, err := a.Acquire(context.Background(), "m"); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("third request error = %v, want ErrQueueFull", err)
+	}
+	if st := a.Status(); st.AvailableSlots != 0 || st.Active != 1 || st.QueueDepth != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+	if load := a.Load(); load != 2 {
+		t.Fatalf("load = %v, want 2 (one running, one queued, one slot)", load)
+	}
+
+	release()
+	if err := <-admitted; err != nil {
+		t.Fatalf("queued request error = %v, want admitted after release", err)
+	}
+	if st := a.Status(); st.AvailableSlots != 1 || st.QueueDepth != 0 {
+		t.Fatalf("status after drain = %+v", st)
+	}
+}
+
+func TestAdmissionQueueTimeout(t *testing.T) {
+	a := newAdmissionController(config.AdmissionConfig{MaxConcurrent: 1, MaxQueue: 4, QueueTimeout: 10 * time.Millisecond})
+	release, _ := a.Acquire(context.Background(), "m")
+	defer release()
+
+	if _, err := a.Acquire(context.Background(), "m"); !errors.Is(err, ErrQueueTimeout) {
+		t.Fatalf("error = %v, want ErrQueueTimeout", err)
+	}
+	if st := a.Status(); st.QueueDepth != 0 {
+		t.Fatalf("queue depth = %d after timeout, want 0", st.QueueDepth)
+	}
+}
+
+func TestAdmissionPerModelLimitDoesNotBlockOtherModels(t *testing.T) {
+	a := newAdmissionController(config.AdmissionConfig{
+		MaxConcurrent:         4,
+		MaxConcurrentPerModel: 1,
+		MaxQueue:              4,
+		QueueTimeout:          time.Second,
+	})
+	releaseA, _ := a.Acquire(context.Background(), "a")
+
+	queuedA := make(chan func(), 1)
+	go func() {
+		r, err := a.Acquire(context.Background(), "a")
+		if err != nil {
+			t.Error(err)
+		}
+		queuedA <- r
+	}()
+	waitForQueueDepth(t, a, 1)
+
+	// Model b is admitted immediately even though a request for a is queued.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	releaseB, err := a.Acquire(ctx, "b")
+	if err != nil {
+		t.Fatalf("model b error = %v, want immediate admission", err)
+	}
+	releaseB()
+
+	releaseA()
+	(<-queuedA)()
+	if st := a.Status(); st.Active != 0 {
+		t.Fatalf("active = %d after all releases, want 0", st.Active)
+	}
+}
+
+func waitForQueueDepth(t *testing.T, a *admissionController, depth int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for a.Status().QueueDepth != depth {
+		if time.Now().After(deadline) {
+			t.Fatalf("queue depth never reached %d", depth)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// deadlineWriteGrace keeps the connection writable after a request deadline
+// expires, so the 504 body can still reach the consumer.
+const deadlineWriteGrace = 5 * time.Second
+
+// requestContext derives a context bounded by the configured deadline for route
+// and model, and extends the connection write deadline to match. A zero
+// deadline clears the write deadline, so the server's WriteTimeout backstop
+// does not cut the response off either.
+func (s *Server) requestContext(w http.ResponseWriter, r *http.Request, route, model string) (context.Context, context.CancelFunc) {
+	d := s.config.Server.Deadlines.For(route, model)
+	// Not every ResponseWriter supports deadlines (e.g. httptest); ignore that case.
+	rc := http.NewResponseController(w)
+	if d <= 0 {
+		_ = rc.SetWriteDeadline(time.Time{})
+		return context.WithCancel(r.Context())
+	}
+	_ = rc.SetWriteDeadline(time.Now().Add(d + deadlineWriteGrace))
+	return context.WithTimeout(r.Context(), d)
+}
+
+// withRouteDeadline applies the route deadline to handlers that do not depend on a model.
+func (s *Server) withRouteDeadline(route string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := s.requestContext(w, r, route, "")
+		defer cancel()
+		h(w, r.WithContext(ctx))
+	}
+}
+
+// deadlineExceeded reports whether ctx ended because its deadline passed
+func deadlineExceeded(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// deadlineError builds the OpenAI-style body returned when a request deadline expires
+func deadlineError(route string) OpenAIErrorResponse {
+	return OpenAIErrorResponse{Error: OpenAIError{
+		Message: fmt.Sprintf("request to %s exceeded its deadline", route),
+		Type:    "timeout",
+		Code:    "deadline_exceeded",
+	}}
+}
+
+// writeDeadlineExceeded writes a 504 with an OpenAI-style error body
+func writeDeadlineExceeded(w http.ResponseWriter, route string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusGatewayTimeout)
+	json.NewEncoder(w).Encode(deadlineError(route))
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/energy"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// SetEnergyMeter attaches the per-request GPU energy meter (optional).
+func (s *Server) SetEnergyMeter(m *energy.Meter) {
+	s.energy = m
+}
+
+// endMetering closes a request's energy reading and logs what it used.
+func (s *Server) endMetering(r *energy.Request, usage llm.TokenUsage) {
+	if r == nil {
+		return
+	}
+	joules := s.energy.End(r, energy.Usage{
+		PromptTokens:     int64(usage.PromptTokens),
+		CompletionTokens: int64(usage.CompletionTokens),
+	})
+	logger.Debug("Request energy",
+		zap.String("model", r.Model),
+		zap.Float64("joules", joules),
+		zap.Int("prompt_tokens", usage.PromptTokens),
+		zap.Int("completion_tokens", usage.CompletionTokens))
+}
+
+// handleStats serves request counters and energy totals. Earnings and power
+// figures are for the operator only, so tunnelled requests are refused.
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		http.Error(w, "stats are only available locally", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(StatsResponse{Requests: s.RequestStats().Counts, Energy: s.energy.Stats()})
+}
+
+// isLocalRequest reports whether r comes from this machine rather than
+// through the Cloudflare tunnel. cloudflared also connects from loopback,
+// so tunnelled requests are told apart by the header Cloudflare adds.
+func isLocalRequest(r *http.Request) bool {
+	if r.Header.Get("Cf-Connecting-Ip") != "" {
+		return false
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// writeEnergy adds metered energy, its estimated cost and estimated earnings to the console.
+func (s *Server) writeEnergy(buf *bytes.Buffer) {
+	if s.energy == nil {
+		return
+	}
+	stats := s.energy.Stats()
+	line := fmt.Sprintf("%.2f Wh over %d requests", stats.Joules/3600, stats.Requests)
+	if stats.PricePerKWh > 0 {
+		line += fmt.Sprintf("  cost ≈ $%.4f", stats.EstimatedCost)
+	}
+	line += fmt.Sprintf("  earnings ≈ $%.4f", stats.EstimatedEarnings)
+	buf.WriteString(fmt.Sprintf("\033[1;35mEnergy                       \033[0m%s\n", line))
+	for _, m := range stats.Models {
+		buf.WriteString(fmt.Sprintf("  %-32s %8.2f Wh  %6d req  $%.4f / $%.4f\n",
+			m.Model, m.Joules/3600, m.Requests, m.EstimatedCost, m.EstimatedEarnings))
+	}
+}
@@ -0,0 +1,76 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/energy"
+)
+
+func TestInferenceIsMeteredAndReportedByStats(t *testing.T) {
+	node := nodeStub(t, true)
+	fake := &fakeLLM{
+		forwardResp: []byte(`{"choices":[],"usage":{"prompt_tokens":10,"completion_tokens":20,"total_tokens":30}}`),
+		streamResp: []string{
+			"data: {\"choices\":[{\"delta\":{\"content\":\"hi\"}}]}\n\n",
+			"data: {\"choices\":[],\"usage\":{\"prompt_tokens\":3,\"completion_tokens\":4,\"total_tokens\":7}}\n\n",
+			"data: [DONE]\n\n",
+		},
+	}
+	s := newTestServer(node.URL, fake)
+
+	// Every read of the counter finds another 50 J drawn.
+	joules := 0.0
+	s.SetEnergyMeter(energy.NewMeter(func() float64 { joules += 50; return joules }, energy.Config{PricePerKWh: 0.30}))
+
+	if rec := postChat(s, "good", `{"model":"m"}`); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec := postChat(s, "good", `{"model":"m","stream":true}`); rec.Code != http.StatusOK {
+		t.Fatalf("stream status = %d, want 200", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+	req.RemoteAddr = "127.0.0.1:50000"
+	rec := httptest.NewRecorder()
+	s.handleStats(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("stats status = %d, want 200", rec.Code)
+	}
+	var stats StatsResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &stats); err != nil {
+		t.Fatal(err)
+	}
+	if stats.Requests.Success != 2 || stats.Energy.Requests != 2 || len(stats.Energy.Models) != 1 {
+		t.Fatalf("stats = %+v", stats)
+	}
+	model := stats.Energy.Models[0]
+	if model.Model != "m" || model.PromptTokens != 13 || model.CompletionTokens != 24 {
+		t.Fatalf("model stats = %+v, want m with 13/24 tokens", model)
+	}
+	if model.Joules != 100 || model.EstimatedCost <= 0 {
+		t.Fatalf("model energy = %v J cost %v, want 100 J with a cost", model.Joules, model.EstimatedCost)
+	}
+}
+
+func TestStatsRefusesTunnelledRequests(t *testing.T) {
+	s := newTestServer("http://unused", &fakeLLM{})
+	cases := map[string]func(*http.Request){
+		"remote address": func(r *http.Request) { r.RemoteAddr = "203.0.113.7:443" },
+		"via cloudflared": func(r *http.Request) {
+			r.RemoteAddr = "127.0.0.1:50000"
+			r.Header.Set("Cf-Connecting-Ip", "203.0.113.7")
+		},
+	}
+	for name, setup := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/api/stats", nil)
+		setup(req)
+		rec := httptest.NewRecorder()
+		s.handleStats(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("%s: status = %d, want 403", name, rec.Code)
+		}
+	}
+}
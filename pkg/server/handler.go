@@ -1,13 +1,20 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/usermsg"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
 )
 
 // handleHealth handles the /api/health endpoint
@@ -17,6 +24,12 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	// Get health report
 	report, err := s.healthReporter.GetHealthReport(r.Context())
 	if err != nil {
+		if deadlineExceeded(r.Context()) {
+			s.logError(fmt.Sprintf("Health report exceeded its deadline: %v", err))
+			writeDeadlineExceeded(w, r.URL.Path)
+			s.logRequest(r.Method, r.URL.Path, http.StatusGatewayTimeout, startTime)
+			return
+		}
 		s.logError(fmt.Sprintf("Failed to get health report: %v", err))
 		http.Error(w, usermsg.HTTP(err, s.config.Provider.ProviderType), http.StatusInternalServerError)
 		s.logRequest(r.Method, r.URL.Path, http.StatusInternalServerError, startTime)
@@ -33,71 +46,100 @@ func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleBusy(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
 
-	// Check if GPU is busy
-	var isBusy bool
-	var err error
+	// Busy means a new request would have to queue for a slot, or would be
+	// refused because GPU protection has paused serving
+	status := s.admission.Status()
+	pause := s.gpuPause()
+	resp := BusyResponse{
+		Busy:           status.AvailableSlots == 0 || pause.Paused,
+		AvailableSlots: status.AvailableSlots,
+		ActiveRequests: status.Active,
+		QueueDepth:     status.QueueDepth,
+		MaxQueue:       status.MaxQueue,
+		Paused:         pause.Paused,
+		PauseReason:    pause.Reason,
+	}
+	if pause.Paused {
+		resp.ResumeAt = &pause.ResumeAt
+	}
 
-	if s.gpuMonitor != nil {
-		isBusy, err = s.gpuMonitor.IsBusy()
+	// Write response
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+	s.logRequest(r.Method, r.URL.Path, http.StatusOK, startTime)
+}
+
+// handleModels handles the /v1/models endpoint, listing only models verified for inference
+func (s *Server) handleModels(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+
+	models := s.healthReporter.GetDisplayedModels()
+	if models == nil {
+		// Nothing cached before the first health sync; poll the backend once.
+		var err error
+		models, err = s.healthReporter.RefreshModelsForDisplay(r.Context())
 		if err != nil {
-			s.logError(fmt.Sprintf("Error checking if GPU is busy: %v", err))
-			http.Error(w, fmt.Sprintf("Failed to check if GPU is busy: %v", err), http.StatusInternalServerError)
-			s.logRequest(r.Method, r.URL.Path, http.StatusInternalServerError, startTime)
+			s.logError(fmt.Sprintf("Failed to list models: %v", err))
+			http.Error(w, usermsg.HTTP(err, s.config.Provider.ProviderType), http.StatusBadGateway)
+			s.logRequest(r.Method, r.URL.Path, http.StatusBadGateway, startTime)
 			return
 		}
-	} else {
-		// If GPU monitor is not available, assume not busy
-		isBusy = false
 	}
 
 	// Write response
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(BusyResponse{Busy: isBusy})
+	json.NewEncoder(w).Encode(verifiedModelList(models))
 	s.logRequest(r.Method, r.URL.Path, http.StatusOK, startTime)
 }
 
+// verifiedModelList converts enriched models to an OpenAI model list, dropping models not allowed to serve
+func verifiedModelList(models []llm.Model) ModelsResponse {
+	resp := ModelsResponse{Object: "list", Data: make([]ModelObject, 0, len(models))}
+	for _, m := range models {
+		if !verify.IsInferenceAllowed(m.VerificationStatus) {
+			continue
+		}
+		object := m.Object
+		if object == "" {
+			object = "model"
+		}
+		resp.Data = append(resp.Data, ModelObject{
+			ID:      m.ID,
+			Object:  object,
+			Created: m.Created,
+			OwnedBy: m.OwnedBy,
+			Inferoute: ModelVerification{
+				VerificationStatus: m.VerificationStatus,
+				Digest:             m.Digest,
+				WeightFingerprint:  m.WeightFingerprint,
+				SizeBytes:          m.SizeBytes,
+			},
+		})
+	}
+	return resp
+}
+
 // handleChatCompletions handles the /v1/chat/completions endpoint
 func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
-	startTime := time.Now()
+	s.proxyInference(w, r, "/v1/chat/completions")
+}
 
-	// Check if GPU is busy
-	var isBusy bool
-	var err error
+// handleCompletions handles the /v1/completions endpoint
+func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
+	s.proxyInference(w, r, "/v1/completions")
+}
 
-	if s.gpuMonitor != nil {
-		isBusy, err = s.gpuMonitor.IsBusy()
-		if err != nil {
-			s.logError(fmt.Sprintf("Error checking if GPU is busy: %v", err))
-			http.Error(w, fmt.Sprintf("Failed to check if GPU is busy: %v", err), http.StatusInternalServerError)
-			s.logRequest(r.Method, r.URL.Path, http.StatusInternalServerError, startTime)
-			return
-		}
-	} else {
-		// If GPU monitor is not available, assume not busy
-		isBusy = false
-	}
+// handleEmbeddings handles the /v1/embeddings endpoint
+func (s *Server) handleEmbeddings(w http.ResponseWriter, r *http.Request) {
+	s.proxyInference(w, r, "/v1/embeddings")
+}
 
-	// If GPU is busy, return error
-	if isBusy {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "GPU is busy"})
-		s.logRequest(r.Method, r.URL.Path, http.StatusServiceUnavailable, startTime)
-		return
-	}
+// proxyInference runs the HMAC, model verification and admission checks and forwards the request to the LLM provider
+func (s *Server) proxyInference(w http.ResponseWriter, r *http.Request, path string) {
+	startTime := time.Now()
 
-	// Validate HMAC from X-Request-Id header
 	hmac := r.Header.Get("X-Request-Id")
-	if hmac != "" {
-		if err := s.validateHMAC(r.Context(), hmac); err != nil {
-			s.logError(fmt.Sprintf("HMAC validation failed: %v", err))
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Invalid HMAC: %v", err)})
-			s.logRequest(r.Method, r.URL.Path, http.StatusUnauthorized, startTime)
-			return
-		}
-	} else {
+	if hmac == "" {
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusUnauthorized)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: "Missing HMAC in X-Request-Id header"})
@@ -114,100 +156,109 @@ func (s *Server) handleChatCompletions(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.verifyModelInRequest(r.Context(), body); err != nil {
-		s.logError(fmt.Sprintf("Model verification failed: %v", err))
+	// Bound HMAC validation, queueing and generation by the route/model deadline
+	model := requestModel(body)
+	ctx, cancel := s.requestContext(w, r, path, model)
+	defer cancel()
+
+	// Validate HMAC from X-Request-Id header
+	if err := s.validateHMAC(ctx, hmac); err != nil {
+		if deadlineExceeded(ctx) {
+			s.logError(fmt.Sprintf("HMAC validation exceeded the request deadline: %v", err))
+			writeDeadlineExceeded(w, path)
+			s.logRequest(r.Method, r.URL.Path, http.StatusGatewayTimeout, startTime)
+			return
+		}
+		s.logError(fmt.Sprintf("HMAC validation failed: %v", err))
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
-		s.logRequest(r.Method, r.URL.Path, http.StatusForbidden, startTime)
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Invalid HMAC: %v", err)})
+		s.logRequest(r.Method, r.URL.Path, http.StatusUnauthorized, startTime)
 		return
 	}
 
-	// Forward request to LLM provider
-	llmResp, err := s.forwardToLLM(r.Context(), "/v1/chat/completions", body)
-	if err != nil {
-		s.logError(fmt.Sprintf("Failed to forward request to LLM provider: %v", err))
-		http.Error(w, usermsg.HTTP(err, s.config.Provider.ProviderType), http.StatusBadGateway)
-		s.logRequest(r.Method, r.URL.Path, http.StatusBadGateway, startTime)
+	if err := validateInferenceBody(path, body); err != nil {
+		s.logError(fmt.Sprintf("Invalid request body: %v", err))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		s.logRequest(r.Method, r.URL.Path, http.StatusBadRequest, startTime)
 		return
 	}
 
-	// Write response
-	w.Header().Set("Content-Type", "application/json")
-	w.Write(llmResp)
-	s.logRequest(r.Method, r.URL.Path, http.StatusOK, startTime)
-}
-
-// handleCompletions handles the /v1/completions endpoint
-func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
-	startTime := time.Now()
-
-	// Check if GPU is busy
-	var isBusy bool
-	var err error
-
-	if s.gpuMonitor != nil {
-		isBusy, err = s.gpuMonitor.IsBusy()
-		if err != nil {
-			s.logError(fmt.Sprintf("Error checking if GPU is busy: %v", err))
-			http.Error(w, fmt.Sprintf("Failed to check if GPU is busy: %v", err), http.StatusInternalServerError)
-			s.logRequest(r.Method, r.URL.Path, http.StatusInternalServerError, startTime)
-			return
-		}
-	} else {
-		// If GPU monitor is not available, assume not busy
-		isBusy = false
-	}
-
-	// If GPU is busy, return error
-	if isBusy {
+	// Refuse new work while GPU protection has paused serving
+	if pause := s.gpuPause(); pause.Paused {
+		s.logError(fmt.Sprintf("Inference paused: %s", pause.Reason))
 		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Retry-After", retryAfterSeconds(pause.ResumeAt))
 		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "GPU is busy"})
+		json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Inference paused by GPU protection: %s", pause.Reason)})
 		s.logRequest(r.Method, r.URL.Path, http.StatusServiceUnavailable, startTime)
 		return
 	}
 
-	// Validate HMAC from X-Request-Id header
-	hmac := r.Header.Get("X-Request-Id")
-	if hmac != "" {
-		if err := s.validateHMAC(r.Context(), hmac); err != nil {
-			s.logError(fmt.Sprintf("HMAC validation failed: %v", err))
+	if err := s.verifyModelInRequest(body); err != nil {
+		if errors.Is(err, verify.ErrReverifying) || errors.Is(err, verify.ErrVerificationPending) {
+			// Verification is running in the background; the model comes back shortly
 			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusUnauthorized)
-			json.NewEncoder(w).Encode(ErrorResponse{Error: fmt.Sprintf("Invalid HMAC: %v", err)})
-			s.logRequest(r.Method, r.URL.Path, http.StatusUnauthorized, startTime)
+			w.Header().Set("Retry-After", "5")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			s.logRequest(r.Method, r.URL.Path, http.StatusServiceUnavailable, startTime)
 			return
 		}
-	} else {
+		s.logError(fmt.Sprintf("Model verification failed: %v", err))
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusUnauthorized)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: "Missing HMAC in X-Request-Id header"})
-		s.logRequest(r.Method, r.URL.Path, http.StatusUnauthorized, startTime)
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		s.logRequest(r.Method, r.URL.Path, http.StatusForbidden, startTime)
 		return
 	}
 
-	// Read request body
-	body, err := io.ReadAll(r.Body)
+	// Wait for a free node and model slot
+	release, err := s.admission.Acquire(ctx, model)
 	if err != nil {
-		s.logError(fmt.Sprintf("Failed to read request body: %v", err))
-		http.Error(w, fmt.Sprintf("Failed to read request body: %v", err), http.StatusBadRequest)
-		s.logRequest(r.Method, r.URL.Path, http.StatusBadRequest, startTime)
+		switch {
+		case clientCancelled(r.Context()):
+			s.logRequest(r.Method, r.URL.Path, StatusClientClosedRequest, startTime)
+		case deadlineExceeded(ctx):
+			writeDeadlineExceeded(w, path)
+			s.logRequest(r.Method, r.URL.Path, http.StatusGatewayTimeout, startTime)
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			s.logRequest(r.Method, r.URL.Path, http.StatusServiceUnavailable, startTime)
+		}
 		return
 	}
+	defer release()
 
-	if err := s.verifyModelInRequest(r.Context(), body); err != nil {
-		s.logError(fmt.Sprintf("Model verification failed: %v", err))
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusForbidden)
-		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
-		s.logRequest(r.Method, r.URL.Path, http.StatusForbidden, startTime)
+	// Meter GPU energy from admission until the response is complete
+	var usage llm.TokenUsage
+	metered := s.energy.Begin(model)
+	defer func() { s.endMetering(metered, usage) }()
+
+	if llm.IsStreamRequest(body) {
+		usage = s.streamFromLLM(ctx, w, r, path, body, startTime)
 		return
 	}
 
 	// Forward request to LLM provider
-	llmResp, err := s.forwardToLLM(r.Context(), "/v1/completions", body)
+	llmResp, err := s.forwardToLLM(ctx, path, body)
 	if err != nil {
+		if clientCancelled(r.Context()) {
+			// The consumer hung up; the backend request was aborted with it.
+			s.logRequest(r.Method, r.URL.Path, StatusClientClosedRequest, startTime)
+			return
+		}
+		if deadlineExceeded(ctx) {
+			s.logError(fmt.Sprintf("LLM request exceeded its deadline: %v", err))
+			writeDeadlineExceeded(w, path)
+			s.logRequest(r.Method, r.URL.Path, http.StatusGatewayTimeout, startTime)
+			return
+		}
 		s.logError(fmt.Sprintf("Failed to forward request to LLM provider: %v", err))
 		http.Error(w, usermsg.HTTP(err, s.config.Provider.ProviderType), http.StatusBadGateway)
 		s.logRequest(r.Method, r.URL.Path, http.StatusBadGateway, startTime)
@@ -215,7 +266,74 @@ func (s *Server) handleCompletions(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Write response
+	usage, _ = llm.ParseUsage(llmResp)
 	w.Header().Set("Content-Type", "application/json")
 	w.Write(llmResp)
 	s.logRequest(r.Method, r.URL.Path, http.StatusOK, startTime)
 }
+
+// streamFromLLM relays a stream:true response from the LLM provider as server-sent events
+// and returns the token usage reported in the final chunk, if any
+func (s *Server) streamFromLLM(ctx context.Context, w http.ResponseWriter, r *http.Request, path string, body []byte, startTime time.Time) llm.TokenUsage {
+	// Cancel the backend stream as soon as the consumer stops reading.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sw := newSSEWriter(w, cancel)
+	if err := s.forwardStreamToLLM(ctx, path, body, sw); err != nil {
+		if clientCancelled(r.Context()) || sw.writeErr != nil {
+			s.logRequest(r.Method, r.URL.Path, StatusClientClosedRequest, startTime)
+			return sw.usage
+		}
+		if deadlineExceeded(ctx) {
+			s.logError(fmt.Sprintf("LLM stream exceeded its deadline: %v", err))
+			if sw.started {
+				// Headers are already sent; end the stream with an error event.
+				payload, _ := json.Marshal(deadlineError(path))
+				fmt.Fprintf(w, "data: %s\n\n", payload)
+				sw.Flush()
+			} else {
+				writeDeadlineExceeded(w, path)
+			}
+			s.logRequest(r.Method, r.URL.Path, http.StatusGatewayTimeout, startTime)
+			return sw.usage
+		}
+		if !sw.started {
+			s.logError(fmt.Sprintf("Failed to forward request to LLM provider: %v", err))
+			http.Error(w, usermsg.HTTP(err, s.config.Provider.ProviderType), http.StatusBadGateway)
+			s.logRequest(r.Method, r.URL.Path, http.StatusBadGateway, startTime)
+			return sw.usage
+		}
+		// Headers are already sent; the consumer sees a truncated stream.
+		s.logError(fmt.Sprintf("Stream from LLM provider interrupted: %v", err))
+	}
+	s.logRequest(r.Method, r.URL.Path, http.StatusOK, startTime)
+	return sw.usage
+}
+
+// validateInferenceBody checks route-specific request fields before anything is sent to the LLM provider
+func validateInferenceBody(path string, body []byte) error {
+	switch path {
+	case "/v1/embeddings":
+		return llm.ValidateEmbeddingRequest(body)
+	default:
+		return nil
+	}
+}
+
+// gpuPause returns the GPU protection state; serving is never paused without a GPU monitor.
+func (s *Server) gpuPause() gpu.PauseState {
+	if s.gpuMonitor == nil {
+		return gpu.PauseState{}
+	}
+	return s.gpuMonitor.Paused()
+}
+
+// retryAfterSeconds formats the Retry-After header for a pause ending at resumeAt.
+func retryAfterSeconds(resumeAt time.Time) string {
+	secs := int(math.Ceil(time.Until(resumeAt).Seconds()))
+	if secs < 1 {
+		secs = 1
+	}
+	return strconv.Itoa(secs)
+}
@@ -3,13 +3,17 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/sentnl/inferoute-node/inferoute-client/internal/config"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
 	"go.uber.org/zap"
@@ -24,8 +28,11 @@ func TestMain(m *testing.M) {
 type fakeLLM struct {
 	forwardResp []byte
 	forwardErr  error
+	streamResp  []string
+	blockUntil  chan struct{} // when set, closed on entry; forwarding then blocks until ctx is done
 	gotPath     string
 	gotBody     []byte
+	gotCtxErr   error
 }
 
 func (f *fakeLLM) ListModels(ctx context.Context) (*llm.ListModelsResponse, error) { return nil, nil }
@@ -34,8 +41,35 @@ func (f *fakeLLM) Chat(ctx context.Context, r *llm.ChatRequest) (*llm.ChatRespon
 }
 func (f *fakeLLM) ForwardRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
 	f.gotPath, f.gotBody = path, body
+	if f.blockUntil != nil {
+		close(f.blockUntil)
+		<-ctx.Done()
+		f.gotCtxErr = ctx.Err()
+		return nil, ctx.Err()
+	}
 	return f.forwardResp, f.forwardErr
 }
+func (f *fakeLLM) ForwardStream(ctx context.Context, path string, body []byte, w llm.StreamWriter) error {
+	f.gotPath, f.gotBody = path, body
+	if f.blockUntil != nil {
+		close(f.blockUntil)
+		<-ctx.Done()
+		f.gotCtxErr = ctx.Err()
+		return ctx.Err()
+	}
+	if f.forwardErr != nil {
+		return f.forwardErr
+	}
+	for _, event := range f.streamResp {
+		if _, err := w.Write([]byte(event)); err != nil {
+			return err
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
 // nodeStub returns an httptest server that answers the HMAC validation endpoint.
 func nodeStub(t *testing.T, valid bool) *httptest.Server {
@@ -56,7 +90,7 @@ func newTestServer(nodeURL string, llmClient llm.Client) *Server {
 	cfg.Provider.URL = nodeURL
 	cfg.Provider.APIKey = "test-key"
 	cfg.Provider.ProviderType = "ollama"
-	return &Server{config: cfg, llmClient: llmClient}
+	return &Server{config: cfg, llmClient: llmClient, admission: newAdmissionController(cfg.Server.Admission)}
 }
 
 func postChat(s *Server, hmac, body string) *httptest.ResponseRecorder {
@@ -103,9 +137,270 @@ func TestHandleChatCompletionsGuardChain(t *testing.T) {
 	})
 }
 
+func TestHandleChatCompletionsStream(t *testing.T) {
+	t.Run("stream:true relays events as text/event-stream", func(t *testing.T) {
+		node := nodeStub(t, true)
+		fake := &fakeLLM{streamResp: []string{"data: {\"id\":1}\n\n", "data: [DONE]\n\n"}}
+		s := newTestServer(node.URL, fake)
+
+		rec := postChat(s, "good-hmac", `{"model":"m","stream":true}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+		}
+		if want := "data: {\"id\":1}\n\ndata: [DONE]\n\n"; rec.Body.String() != want {
+			t.Fatalf("body = %q, want %q", rec.Body.String(), want)
+		}
+		if !rec.Flushed {
+			t.Fatal("expected stream to be flushed")
+		}
+	})
+
+	t.Run("backend error before first event returns 502", func(t *testing.T) {
+		node := nodeStub(t, true)
+		fake := &fakeLLM{forwardErr: llm.ErrHTTP}
+		s := newTestServer(node.URL, fake)
+
+		if got := postChat(s, "good-hmac", `{"model":"m","stream":true}`).Code; got != http.StatusBadGateway {
+			t.Fatalf("status = %d, want 502", got)
+		}
+	})
+}
+
+func TestClientDisconnectCancelsBackend(t *testing.T) {
+	for _, body := range []string{`{"model":"m"}`, `{"model":"m","stream":true}`} {
+		t.Run(body, func(t *testing.T) {
+			node := nodeStub(t, true)
+			fake := &fakeLLM{blockUntil: make(chan struct{})}
+			s := newTestServer(node.URL, fake)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body)).WithContext(ctx)
+			req.Header.Set("X-Request-Id", "good-hmac")
+			rec := httptest.NewRecorder()
+
+			done := make(chan struct{})
+			go func() {
+				s.handleChatCompletions(rec, req)
+				close(done)
+			}()
+			<-fake.blockUntil
+			cancel()
+			<-done
+
+			if !errors.Is(fake.gotCtxErr, context.Canceled) {
+				t.Fatalf("backend context error = %v, want context.Canceled", fake.gotCtxErr)
+			}
+			if s.requestStats.ClientCancelled != 1 || s.requestStats.Errors != 0 || s.requestStats.Success != 0 {
+				t.Fatalf("stats = cancelled:%d errors:%d success:%d, want only client_cancelled",
+					s.requestStats.ClientCancelled, s.requestStats.Errors, s.requestStats.Success)
+			}
+		})
+	}
+}
+
+func TestRequestDeadlineReturns504(t *testing.T) {
+	for _, body := range []string{`{"model":"slow"}`, `{"model":"slow","stream":true}`} {
+		t.Run(body, func(t *testing.T) {
+			node := nodeStub(t, true)
+			fake := &fakeLLM{blockUntil: make(chan struct{})}
+			s := newTestServer(node.URL, fake)
+			s.config.Server.Deadlines.Routes = map[string]time.Duration{"/v1/chat/completions": time.Hour}
+			s.config.Server.Deadlines.Models = map[string]time.Duration{"slow": 20 * time.Millisecond}
+
+			rec := postChat(s, "good-hmac", body)
+			if rec.Code != http.StatusGatewayTimeout {
+				t.Fatalf("status = %d, want 504", rec.Code)
+			}
+			if !errors.Is(fake.gotCtxErr, context.DeadlineExceeded) {
+				t.Fatalf("backend context error = %v, want context.DeadlineExceeded", fake.gotCtxErr)
+			}
+			var resp OpenAIErrorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("body %q is not an OpenAI error: %v", rec.Body.String(), err)
+			}
+			if resp.Error.Code != "deadline_exceeded" || resp.Error.Type != "timeout" {
+				t.Fatalf("error = %+v, want timeout/deadline_exceeded", resp.Error)
+			}
+		})
+	}
+}
+
+// slowLLM answers forwarded requests after delay.
+type slowLLM struct {
+	fakeLLM
+	delay time.Duration
+}
+
+func (f *slowLLM) ForwardRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	time.Sleep(f.delay)
+	return f.fakeLLM.ForwardRequest(ctx, path, body)
+}
+
+func TestZeroDeadlineOutlivesWriteTimeout(t *testing.T) {
+	node := nodeStub(t, true)
+	s := newTestServer(node.URL, &slowLLM{fakeLLM: fakeLLM{forwardResp: []byte(`{"id":"done"}`)}, delay: 200 * time.Millisecond})
+	s.config.Server.Deadlines.Routes = map[string]time.Duration{"/v1/chat/completions": 20 * time.Millisecond}
+	s.config.Server.Deadlines.Models = map[string]time.Duration{"unlimited": 0}
+
+	ts := httptest.NewUnstartedServer(http.HandlerFunc(s.handleChatCompletions))
+	ts.Config.WriteTimeout = 50 * time.Millisecond
+	ts.Start()
+	defer ts.Close()
+
+	req, _ := http.NewRequest(http.MethodPost, ts.URL, strings.NewReader(`{"model":"unlimited"}`))
+	req.Header.Set("X-Request-Id", "good-hmac")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("response cut off by WriteTimeout: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || string(body) != `{"id":"done"}` {
+		t.Fatalf("status = %d, body %q", resp.StatusCode, body)
+	}
+}
+
+func TestHangingHMACValidationReturns504(t *testing.T) {
+	release := make(chan struct{})
+	node := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer node.Close()
+	defer close(release)
+
+	fake := &fakeLLM{}
+	s := newTestServer(node.URL, fake)
+	s.config.Server.Deadlines.Models = map[string]time.Duration{"slow": 20 * time.Millisecond}
+
+	rec := postChat(s, "good-hmac", `{"model":"slow"}`)
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want 504", rec.Code)
+	}
+	if fake.gotPath != "" {
+		t.Fatal("request forwarded without a validated HMAC")
+	}
+}
+
+func TestHandleEmbeddings(t *testing.T) {
+	post := func(s *Server, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(body))
+		req.Header.Set("X-Request-Id", "good-hmac")
+		rec := httptest.NewRecorder()
+		s.handleEmbeddings(rec, req)
+		return rec
+	}
+
+	t.Run("array input forwarded with usage", func(t *testing.T) {
+		node := nodeStub(t, true)
+		fake := &fakeLLM{forwardResp: []byte(`{"object":"list","data":[{"object":"embedding","index":0,"embedding":[0.1]},{"object":"embedding","index":1,"embedding":[0.2]}],"model":"m","usage":{"prompt_tokens":4,"total_tokens":4}}`)}
+		s := newTestServer(node.URL, fake)
+
+		rec := post(s, `{"model":"m","input":["a","b"]}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200", rec.Code)
+		}
+		if fake.gotPath != "/v1/embeddings" {
+			t.Fatalf("forwarded path = %q", fake.gotPath)
+		}
+		var resp struct {
+			Data  []json.RawMessage `json:"data"`
+			Usage llm.TokenUsage    `json:"usage"`
+		}
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Data) != 2 || resp.Usage.PromptTokens != 4 || resp.Usage.TotalTokens != 4 {
+			t.Fatalf("response = %+v, want 2 vectors and usage passed through", resp)
+		}
+	})
+
+	t.Run("invalid input returns 400 without forwarding", func(t *testing.T) {
+		node := nodeStub(t, true)
+		fake := &fakeLLM{}
+		s := newTestServer(node.URL, fake)
+
+		for _, body := range []string{`{"model":"m","input":[]}`, `{"model":"m","input":"a","stream":true}`} {
+			if got := post(s, body).Code; got != http.StatusBadRequest {
+				t.Fatalf("%s: status = %d, want 400", body, got)
+			}
+		}
+		if fake.gotPath != "" {
+			t.Fatalf("request was forwarded to %q", fake.gotPath)
+		}
+	})
+
+	t.Run("missing HMAC returns 401", func(t *testing.T) {
+		s := newTestServer("http://unused", &fakeLLM{})
+		req := httptest.NewRequest(http.MethodPost, "/v1/embeddings", strings.NewReader(`{"model":"m","input":"x"}`))
+		rec := httptest.NewRecorder()
+		s.handleEmbeddings(rec, req)
+		if rec.Code != http.StatusUnauthorized {
+			t.Fatalf("status = %d, want 401", rec.Code)
+		}
+	})
+}
+
+func TestAdmissionFullReturns503(t *testing.T) {
+	node := nodeStub(t, true)
+	fake := &fakeLLM{forwardResp: []byte(`{}`)}
+	s := newTestServer(node.URL, fake)
+	s.admission = newAdmissionController(config.AdmissionConfig{MaxConcurrent: 1})
+
+	release, err := s.admission.Acquire(context.Background(), "m")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer release()
+
+	rec := postChat(s, "good-hmac", `{"model":"m"}`)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want 503", rec.Code)
+	}
+	if fake.gotPath != "" {
+		t.Fatal("request was forwarded while node was at capacity")
+	}
+}
+
+func TestGPUProtectionPauseReturns503(t *testing.T) {
+	node := nodeStub(t, true)
+	fake := &fakeLLM{forwardResp: []byte(`{}`)}
+	s := newTestServer(node.URL, fake)
+
+	probe, err := gpu.NewFixtureProbe(gpu.VendorNVIDIA, "../gpu/testdata/nvidia-smi-2gpu.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.gpuMonitor = gpu.NewMonitorWithProbe(probe)
+	s.gpuMonitor.SetProtection(gpu.ProtectionConfig{MaxTemperature: 70, Cooldown: time.Minute})
+	if _, err := s.gpuMonitor.Snapshot(); err != nil { // takes the first sample (GPU 0 at 71°C)
+		t.Fatal(err)
+	}
+
+	rec := postChat(s, "good", `{"model":"m"}`)
+	if rec.Code != http.StatusServiceUnavailable || rec.Header().Get("Retry-After") == "" {
+		t.Fatalf("status = %d retry-after = %q, want 503 with Retry-After", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if fake.gotPath != "" {
+		t.Fatal("request was forwarded while serving was paused")
+	}
+
+	busyRec := httptest.NewRecorder()
+	s.handleBusy(busyRec, httptest.NewRequest(http.MethodGet, "/api/busy", nil))
+	var busy BusyResponse
+	if err := json.Unmarshal(busyRec.Body.Bytes(), &busy); err != nil {
+		t.Fatal(err)
+	}
+	if !busy.Busy || !busy.Paused || busy.PauseReason == "" || busy.ResumeAt == nil {
+		t.Fatalf("busy = %+v, want paused with reason and resume time", busy)
+	}
+}
+
 func TestVerifyModelInRequestNilVerifierPasses(t *testing.T) {
 	s := newTestServer("http://unused", &fakeLLM{})
-	if err := s.verifyModelInRequest(context.Background(), []byte(`{"model":"m"}`)); err != nil {
+	if err := s.verifyModelInRequest([]byte(`{"model":"m"}`)); err != nil {
 		t.Fatalf("nil verifier should pass, got %v", err)
 	}
 }
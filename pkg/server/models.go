@@ -3,9 +3,11 @@ package server
 import (
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/sentnl/inferoute-node/inferoute-client/internal/config"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/cloudflare"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/energy"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gpu"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/health"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
@@ -23,19 +25,74 @@ type Server struct {
 	server           *http.Server
 	errorLog         []string
 	errorLogMutex    sync.Mutex
+	admission        *admissionController
+	energy           *energy.Meter
 	requestStats     struct {
-		Total        int
-		Success      int
-		Errors       int
-		Unauthorized int
-		LastRequests []string
-		mutex        sync.Mutex
+		Total           int
+		Success         int
+		Errors          int
+		Unauthorized    int
+		ClientCancelled int
+		LastRequests    []string
+		mutex           sync.Mutex
 	}
 }
 
-// BusyResponse is the response structure for the busy endpoint
+// StatusClientClosedRequest is logged when the consumer disconnects before the
+// response is complete (nginx convention). It is never written to the wire.
+const StatusClientClosedRequest = 499
+
+// BusyResponse is the response structure for the busy endpoint.
+// Busy is true when no inference slot is free and new requests would queue,
+// or while serving is paused by GPU thermal/power protection.
 type BusyResponse struct {
-	Busy bool `json:"busy"`
+	Busy           bool       `json:"busy"`
+	AvailableSlots int        `json:"available_slots"`
+	ActiveRequests int        `json:"active_requests"`
+	QueueDepth     int        `json:"queue_depth"`
+	MaxQueue       int        `json:"max_queue"`
+	Paused         bool       `json:"paused"`
+	PauseReason    string     `json:"pause_reason,omitempty"`
+	ResumeAt       *time.Time `json:"resume_at,omitempty"`
+}
+
+// StatsResponse is the response for the local /api/stats endpoint
+type StatsResponse struct {
+	Requests RequestCounts `json:"requests"`
+	Energy   energy.Stats  `json:"energy"`
+}
+
+// RequestCounts mirrors the request counters shown in the console
+type RequestCounts struct {
+	Total           int `json:"total"`
+	Success         int `json:"success"`
+	Errors          int `json:"errors"`
+	Unauthorized    int `json:"unauthorized"`
+	ClientCancelled int `json:"client_cancelled"`
+}
+
+// ModelsResponse is the OpenAI-compatible response for the /v1/models endpoint
+type ModelsResponse struct {
+	Object string        `json:"object"`
+	Data   []ModelObject `json:"data"`
+}
+
+// ModelObject is one OpenAI model entry. Inferoute-specific fields live under
+// the "inferoute" key so stock OpenAI clients ignore them.
+type ModelObject struct {
+	ID        string            `json:"id"`
+	Object    string            `json:"object"`
+	Created   int64             `json:"created"`
+	OwnedBy   string            `json:"owned_by"`
+	Inferoute ModelVerification `json:"inferoute"`
+}
+
+// ModelVerification carries the integrity verification facts for a served model
+type ModelVerification struct {
+	VerificationStatus string `json:"verification_status"`
+	Digest             string `json:"digest,omitempty"`
+	WeightFingerprint  string `json:"weight_fingerprint,omitempty"`
+	SizeBytes          int64  `json:"size_bytes,omitempty"`
 }
 
 // HMACValidationRequest is the request structure for HMAC validation
@@ -52,3 +109,15 @@ type HMACValidationResponse struct {
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
+
+// OpenAIErrorResponse is the OpenAI-compatible error envelope returned to consumers
+type OpenAIErrorResponse struct {
+	Error OpenAIError `json:"error"`
+}
+
+// OpenAIError is the error object inside OpenAIErrorResponse
+type OpenAIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
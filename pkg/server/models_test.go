@@ -0,0 +1,40 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+func TestVerifiedModelList(t *testing.T) {
+	models := []llm.Model{
+		{ID: "gguf/llama3", Object: "model", OwnedBy: "ollama", Digest: "abc", SizeBytes: 42, VerificationStatus: "verified"},
+		{ID: "gguf/other", Object: "model", OwnedBy: "ollama", VerificationStatus: "unverified"},
+		{ID: "Qwen/Qwen3-0.6B", OwnedBy: "vllm", WeightFingerprint: "fp", VerificationStatus: "verified"},
+		{ID: "org/failed", OwnedBy: "vllm", VerificationStatus: "failed"},
+	}
+
+	resp := verifiedModelList(models)
+	if resp.Object != "list" || len(resp.Data) != 2 {
+		t.Fatalf("response = %+v, want list of 2 verified models", resp)
+	}
+	if resp.Data[0].ID != "gguf/llama3" || resp.Data[0].Inferoute.Digest != "abc" || resp.Data[0].Inferoute.SizeBytes != 42 {
+		t.Fatalf("first model = %+v", resp.Data[0])
+	}
+	if resp.Data[1].Object != "model" || resp.Data[1].Inferoute.WeightFingerprint != "fp" {
+		t.Fatalf("second model = %+v", resp.Data[1])
+	}
+
+	// Stock OpenAI fields stay at the top level; verification lives in the extension object.
+	raw, _ := json.Marshal(resp.Data[0])
+	var generic map[string]interface{}
+	json.Unmarshal(raw, &generic)
+	if _, ok := generic["digest"]; ok {
+		t.Fatal("digest leaked into top-level model object")
+	}
+	ext, ok := generic["inferoute"].(map[string]interface{})
+	if !ok || ext["verification_status"] != "verified" {
+		t.Fatalf("extension object = %v", generic["inferoute"])
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -50,6 +51,7 @@ func CreateServer(cfg *config.Config, gpuMonitor *gpu.Monitor, healthReporter *h
 		llmClient:        llmClient,
 		verifier:         verifier,
 		cloudflareClient: cloudflareClient,
+		admission:        newAdmissionController(cfg.Server.Admission),
 		errorLog:         make([]string, 0, 100),
 	}
 }
@@ -82,17 +84,27 @@ func (s *Server) Start() error {
 	r := mux.NewRouter()
 
 	// Register routes
-	r.HandleFunc("/api/health", s.handleHealth).Methods(http.MethodGet)
-	r.HandleFunc("/api/busy", s.handleBusy).Methods(http.MethodGet)
+	r.HandleFunc("/api/health", s.withRouteDeadline("/api/health", s.handleHealth)).Methods(http.MethodGet)
+	r.HandleFunc("/api/busy", s.withRouteDeadline("/api/busy", s.handleBusy)).Methods(http.MethodGet)
+	r.HandleFunc("/api/stats", s.withRouteDeadline("/api/stats", s.handleStats)).Methods(http.MethodGet)
+	r.HandleFunc("/api/verification", s.withRouteDeadline("/api/verification", s.handleVerification)).Methods(http.MethodGet)
+	r.HandleFunc("/v1/models", s.withRouteDeadline("/v1/models", s.handleModels)).Methods(http.MethodGet)
 	r.HandleFunc("/v1/chat/completions", s.handleChatCompletions).Methods(http.MethodPost)
 	r.HandleFunc("/v1/completions", s.handleCompletions).Methods(http.MethodPost)
-
-	// Create server
+	r.HandleFunc("/v1/embeddings", s.handleEmbeddings).Methods(http.MethodPost)
+
+	// Create server. Handlers tighten the write deadline per route and model,
+	// or clear it for a zero deadline; WriteTimeout is only a backstop sized
+	// to the longest configured deadline.
+	writeTimeout := 30 * time.Second
+	if longest := s.config.Server.Deadlines.Max(); longest > 0 {
+		writeTimeout = longest + deadlineWriteGrace
+	}
 	s.server = &http.Server{
 		Addr:         fmt.Sprintf("%s:%d", s.config.Server.Host, s.config.Server.Port),
 		Handler:      r,
-		ReadTimeout:  30 * time.Second,
-		WriteTimeout: 30 * time.Second,
+		ReadTimeout:  s.config.Server.ReadTimeout,
+		WriteTimeout: writeTimeout,
 		IdleTimeout:  120 * time.Second,
 	}
 
@@ -127,6 +139,11 @@ func (s *Server) Stop(ctx context.Context) error {
 	return s.server.Shutdown(ctx)
 }
 
+// Load reports inference demand relative to capacity, for dynamic pricing.
+func (s *Server) Load() float64 {
+	return s.admission.Load()
+}
+
 // GetCloudflareClient returns the server's Cloudflare client
 func (s *Server) GetCloudflareClient() *cloudflare.Client {
 	return s.cloudflareClient
@@ -156,7 +173,7 @@ func (s *Server) redrawConsole() {
 	var gpuInfo *gpu.GPUInfo
 	if s.gpuMonitor != nil {
 		var err error
-		gpuInfo, err = s.gpuMonitor.GetGPUInfo()
+		gpuInfo, err = s.gpuMonitor.Snapshot()
 		if err != nil {
 			logger.Error("Failed to get GPU information", zap.Error(err))
 			gpuInfo = &gpu.GPUInfo{
@@ -224,6 +241,28 @@ func (s *Server) redrawConsole() {
 	buf.WriteString(fmt.Sprintf("\033[1;35mDriver Version               \033[0m%s\n", gpuInfo.DriverVersion))
 	buf.WriteString(fmt.Sprintf("\033[1;35mCUDA Version                 \033[0m%s\n", gpuInfo.CUDAVersion))
 	buf.WriteString(fmt.Sprintf("\033[1;35mGPU Count                    \033[0m%d\n", gpuInfo.GPUCount))
+	buf.WriteString(fmt.Sprintf("\033[1;35mUtilization                  \033[0m%.0f%% (avg %.0f%%)\n", gpuInfo.Utilization, gpuInfo.UtilizationSmoothed))
+	if pause := s.gpuPause(); pause.Paused {
+		buf.WriteString(fmt.Sprintf("\033[1;35mServing                      \033[0m\033[1;31mPAUSED\033[0m %s (resumes in %s)\n",
+			pause.Reason, time.Until(pause.ResumeAt).Round(time.Second)))
+	}
+	if gpuInfo.Vendor != gpu.VendorApple {
+		for _, d := range gpuInfo.Devices {
+			marker := " "
+			if d.InUse {
+				marker = "*"
+			}
+			buf.WriteString(fmt.Sprintf("  %s[%d] %-24s %3.0f%%  %4.0f°C  %5.0fW  %d/%d MiB\n",
+				marker, d.Index, d.ProductName, d.UtilizationSmoothed, d.Temperature, d.PowerDraw, d.MemoryUsed, d.MemoryTotal))
+			for _, p := range d.Processes {
+				if p.Foreign {
+					buf.WriteString(fmt.Sprintf("      \033[1;33mowner\033[0m %s (pid %d, %d MiB)\n", p.Name, p.PID, p.UsedMemory))
+				}
+			}
+		}
+	}
+
+	s.writeEnergy(&buf)
 
 	// Print last 10 requests
 	buf.WriteString("\n\033[1;33mRecent Requests:\033[0m\n")
@@ -260,6 +299,7 @@ func (s *Server) writeModelStatus(buf *bytes.Buffer) {
 
 	if len(models) == 0 {
 		buf.WriteString("\033[1;35mModel                         \033[0m\033[1;33m(awaiting health sync)\033[0m\n")
+		s.writeVerification(buf)
 		return
 	}
 
@@ -269,9 +309,13 @@ func (s *Server) writeModelStatus(buf *bytes.Buffer) {
 			prefix = "                                "
 		}
 		label, color := usermsg.ApprovalConsole(m.VerificationStatus)
+		if m.VerificationReason != "" {
+			label += " (" + usermsg.VerificationReason(m.VerificationReason) + ")"
+		}
 		buf.WriteString(fmt.Sprintf("\033[1;35m%s\033[0m%s\n", prefix, m.ID))
 		buf.WriteString(fmt.Sprintf("\033[1;35mMarketplace approval          \033[0m%s%s\033[0m\n", color, label))
 	}
+	s.writeVerification(buf)
 }
 
 // printStartupBanner prints a nice startup banner with GPU info
@@ -287,6 +331,11 @@ func (s *Server) logRequest(method, path string, statusCode int, startTime time.
 	// Format the log entry
 	var statusColor string
 	switch {
+	case statusCode == StatusClientClosedRequest:
+		statusColor = "\033[1;33m" // Yellow
+		s.requestStats.mutex.Lock()
+		s.requestStats.ClientCancelled++
+		s.requestStats.mutex.Unlock()
 	case statusCode >= 200 && statusCode < 300:
 		statusColor = "\033[1;32m" // Green
 		s.requestStats.mutex.Lock()
@@ -330,9 +379,29 @@ func (s *Server) logRequest(method, path string, statusCode int, startTime time.
 		zap.String("method", method),
 		zap.String("path", path),
 		zap.Int("status", statusCode),
+		zap.String("outcome", requestOutcome(statusCode)),
 		zap.Duration("duration", duration))
 }
 
+// requestOutcome classifies a logged status code for request stats
+func requestOutcome(statusCode int) string {
+	switch {
+	case statusCode == StatusClientClosedRequest:
+		return "client_cancelled"
+	case statusCode >= 200 && statusCode < 300:
+		return "success"
+	case statusCode == 401:
+		return "unauthorized"
+	default:
+		return "error"
+	}
+}
+
+// clientCancelled reports whether the consumer disconnected before the request finished
+func clientCancelled(ctx context.Context) bool {
+	return errors.Is(ctx.Err(), context.Canceled)
+}
+
 // logError logs an error to the error log
 func (s *Server) logError(errMsg string) {
 	timestamp := time.Now().Format("15:04:05.000")
@@ -406,20 +475,36 @@ func (s *Server) validateHMAC(ctx context.Context, hmac string) error {
 	return nil
 }
 
-func (s *Server) verifyModelInRequest(ctx context.Context, body []byte) error {
+// verifyModelInRequest checks the request's model against the verifier's
+// allowlist. It does not wait on verification, which runs in the background.
+func (s *Server) verifyModelInRequest(body []byte) error {
 	if s.verifier == nil {
 		return nil
 	}
+	model := requestModel(body)
+	if model == "" {
+		return fmt.Errorf("missing model in request")
+	}
+	return s.verifier.CheckInference(model)
+}
+
+// requestModel returns the model field of an OpenAI-style request body, or "" if absent
+func requestModel(body []byte) string {
 	var payload struct {
 		Model string `json:"model"`
 	}
-	if err := json.Unmarshal(body, &payload); err != nil || payload.Model == "" {
-		return fmt.Errorf("missing model in request")
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return ""
 	}
-	return s.verifier.CheckInference(ctx, s.llmClient, payload.Model)
+	return payload.Model
 }
 
 // forwardToLLM forwards a request to the LLM provider
 func (s *Server) forwardToLLM(ctx context.Context, path string, body []byte) ([]byte, error) {
 	return s.llmClient.ForwardRequest(ctx, path, body)
 }
+
+// forwardStreamToLLM forwards a streaming request to the LLM provider
+func (s *Server) forwardStreamToLLM(ctx context.Context, path string, body []byte, w llm.StreamWriter) error {
+	return s.llmClient.ForwardStream(ctx, path, body, w)
+}
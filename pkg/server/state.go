@@ -0,0 +1,36 @@
+package server
+
+// RequestStatsState is the console's request counters and recent requests,
+// saved across restarts.
+type RequestStatsState struct {
+	Counts       RequestCounts `json:"counts"`
+	LastRequests []string      `json:"last_requests"`
+}
+
+// RequestStats returns the request counters and recent requests.
+func (s *Server) RequestStats() RequestStatsState {
+	s.requestStats.mutex.Lock()
+	defer s.requestStats.mutex.Unlock()
+	return RequestStatsState{
+		Counts: RequestCounts{
+			Total:           s.requestStats.Total,
+			Success:         s.requestStats.Success,
+			Errors:          s.requestStats.Errors,
+			Unauthorized:    s.requestStats.Unauthorized,
+			ClientCancelled: s.requestStats.ClientCancelled,
+		},
+		LastRequests: append([]string(nil), s.requestStats.LastRequests...),
+	}
+}
+
+// RestoreRequestStats loads saved counters and recent requests.
+func (s *Server) RestoreRequestStats(st RequestStatsState) {
+	s.requestStats.mutex.Lock()
+	defer s.requestStats.mutex.Unlock()
+	s.requestStats.Total = st.Counts.Total
+	s.requestStats.Success = st.Counts.Success
+	s.requestStats.Errors = st.Counts.Errors
+	s.requestStats.Unauthorized = st.Counts.Unauthorized
+	s.requestStats.ClientCancelled = st.Counts.ClientCancelled
+	s.requestStats.LastRequests = st.LastRequests
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+// streamWriteTimeout is the idle budget for each chunk of a streamed response.
+// The connection write deadline is pushed forward on every chunk, so a stream
+// may outlive the server-wide WriteTimeout as long as tokens keep flowing.
+const streamWriteTimeout = 30 * time.Second
+
+// sseWriter adapts an http.ResponseWriter to llm.StreamWriter.
+// Event-stream headers are sent with the first chunk, so backend failures
+// before the stream starts can still be reported with a normal error status.
+// A failed write means the consumer is gone, so cancel aborts the backend request.
+type sseWriter struct {
+	w        http.ResponseWriter
+	rc       *http.ResponseController
+	cancel   context.CancelFunc
+	started  bool
+	writeErr error
+	usage    llm.TokenUsage // from the final chunk, if the backend sent one
+}
+
+func newSSEWriter(w http.ResponseWriter, cancel context.CancelFunc) *sseWriter {
+	return &sseWriter{w: w, rc: http.NewResponseController(w), cancel: cancel}
+}
+
+func (sw *sseWriter) Write(p []byte) (int, error) {
+	// Not every ResponseWriter supports deadlines (e.g. httptest); ignore that case.
+	_ = sw.rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+
+	if !sw.started {
+		sw.started = true
+		h := sw.w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("Connection", "keep-alive")
+		h.Set("X-Accel-Buffering", "no")
+		sw.w.WriteHeader(http.StatusOK)
+	}
+	if usage, ok := llm.ParseUsage(p); ok {
+		sw.usage = usage
+	}
+	n, err := sw.w.Write(p)
+	if err != nil {
+		sw.fail(err)
+	}
+	return n, err
+}
+
+func (sw *sseWriter) Flush() error {
+	if !sw.started {
+		return nil
+	}
+	if err := sw.rc.Flush(); err != nil {
+		sw.fail(err)
+		return err
+	}
+	return nil
+}
+
+func (sw *sseWriter) fail(err error) {
+	if sw.writeErr == nil {
+		sw.writeErr = err
+	}
+	if sw.cancel != nil {
+		sw.cancel()
+	}
+}
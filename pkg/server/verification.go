@@ -0,0 +1,41 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
+)
+
+// VerificationResponse is the body of GET /api/verification.
+type VerificationResponse struct {
+	Progress    []verify.Progress `json:"progress"`    // weight measurements in flight
+	Reverifying []string          `json:"reverifying"` // models blocked after their files changed
+}
+
+// handleVerification serves weight measurement progress and models being
+// re-verified, for the operator only.
+func (s *Server) handleVerification(w http.ResponseWriter, r *http.Request) {
+	if !isLocalRequest(r) {
+		http.Error(w, "verification status is only available locally", http.StatusForbidden)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VerificationResponse{
+		Progress:    s.verifier.Progress(),
+		Reverifying: s.verifier.Reverifying(),
+	})
+}
+
+// writeVerification adds weight measurements in flight to the console.
+func (s *Server) writeVerification(buf *bytes.Buffer) {
+	for _, p := range s.verifier.Progress() {
+		buf.WriteString(fmt.Sprintf("\033[1;35mVerification                  \033[0m%s  \033[1;33m%s\033[0m\n", p.Alias, p))
+	}
+	for _, alias := range s.verifier.Reverifying() {
+		buf.WriteString(fmt.Sprintf("\033[1;35mVerification                  \033[0m%s  \033[1;33mfiles changed, re-verifying\033[0m\n", alias))
+	}
+}
@@ -0,0 +1,245 @@
+// Package state persists what the client learns at runtime (registrations,
+// counters, verification caches, tunnel details) so a restart picks up where
+// the previous run left off.
+package state
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Version is the state file format. A file written by a newer client is set
+// aside instead of being read or overwritten.
+const Version = 1
+
+// FileName is the state file inside the state directory.
+const FileName = "state.json"
+
+// Config locates the state file and sets how often it is written.
+type Config struct {
+	Dir           string        `yaml:"dir"`            // default: the parent of logging.log_dir
+	FlushInterval time.Duration `yaml:"flush_interval"` // default 1m
+}
+
+// DefaultFlushInterval is how often state is written unless configured.
+const DefaultFlushInterval = time.Minute
+
+type file struct {
+	Version  int                        `json:"version"`
+	SavedAt  time.Time                  `json:"saved_at"`
+	Sections map[string]json.RawMessage `json:"sections"`
+}
+
+// Store holds one JSON section per subsystem and writes them all to a single
+// file. Writes go to a temporary file that is synced and renamed over the old
+// one, so a crash leaves either the previous or the new state, never a torn
+// file. Sections nobody registers are carried over untouched. A nil *Store
+// loads nothing and saves nothing.
+type Store struct {
+	path string
+
+	mu       sync.Mutex
+	sections map[string]json.RawMessage
+	sources  map[string]func() any
+	written  []byte // sections as last written, to skip unchanged flushes
+}
+
+// Open reads the state file in dir, creating dir if needed. An unreadable,
+// corrupt or newer file is renamed aside and the store starts empty.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create state directory: %w", err)
+	}
+	s := &Store{
+		path:     filepath.Join(dir, FileName),
+		sections: make(map[string]json.RawMessage),
+		sources:  make(map[string]func() any),
+	}
+
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %w", err)
+	}
+
+	var f file
+	switch err := json.Unmarshal(data, &f); {
+	case err != nil:
+		s.setAside("corrupt", fmt.Sprintf("unreadable: %v", err))
+	case f.Version > Version:
+		s.setAside(fmt.Sprintf("v%d", f.Version), fmt.Sprintf("written by a newer client (format %d)", f.Version))
+	default:
+		if f.Sections != nil {
+			s.sections = f.Sections
+		}
+		logger.Info("Loaded persistent state",
+			zap.String("path", s.path),
+			zap.Time("saved_at", f.SavedAt),
+			zap.Int("sections", len(f.Sections)))
+	}
+	return s, nil
+}
+
+func (s *Store) setAside(suffix, reason string) {
+	aside := s.path + "." + suffix
+	if err := os.Rename(s.path, aside); err != nil {
+		logger.Warn("Failed to set aside state file", zap.String("path", s.path), zap.Error(err))
+	}
+	logger.Warn("Ignoring state file; starting with empty state",
+		zap.String("path", s.path),
+		zap.String("reason", reason),
+		zap.String("moved_to", aside))
+}
+
+// Path returns the state file path.
+func (s *Store) Path() string {
+	if s == nil {
+		return ""
+	}
+	return s.path
+}
+
+// Load decodes the section saved under key into v and reports whether there was one.
+func (s *Store) Load(key string, v any) (bool, error) {
+	if s == nil {
+		return false, nil
+	}
+	s.mu.Lock()
+	raw, ok := s.sections[key]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return false, fmt.Errorf("failed to decode state %q: %w", key, err)
+	}
+	return true, nil
+}
+
+// Register saves snapshot() under key on every flush.
+func (s *Store) Register(key string, snapshot func() any) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sources[key] = snapshot
+}
+
+// Attach restores a subsystem from the section under key, if there is one,
+// and registers its snapshot to be saved there. A section that no longer
+// decodes is dropped with a warning; the subsystem starts fresh.
+func Attach[T any](s *Store, key string, restore func(T), snapshot func() T) {
+	if s == nil {
+		return
+	}
+	var v T
+	ok, err := s.Load(key, &v)
+	if err != nil {
+		logger.Warn("Discarding saved state", zap.String("section", key), zap.Error(err))
+	} else if ok {
+		restore(v)
+	}
+	s.Register(key, func() any { return snapshot() })
+}
+
+// Flush snapshots every registered subsystem and writes the file if anything changed.
+func (s *Store) Flush() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, snapshot := range s.sources {
+		raw, err := json.Marshal(snapshot())
+		if err != nil {
+			return fmt.Errorf("failed to encode state %q: %w", key, err)
+		}
+		s.sections[key] = raw
+	}
+	sections, err := json.Marshal(s.sections)
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if bytes.Equal(sections, s.written) {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(file{Version: Version, SavedAt: time.Now().UTC(), Sections: s.sections}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %w", err)
+	}
+	if err := writeAtomic(s.path, data); err != nil {
+		return err
+	}
+	s.written = sections
+	return nil
+}
+
+// Start flushes every interval until ctx is done. Callers flush once more on shutdown.
+func (s *Store) Start(ctx context.Context, interval time.Duration) {
+	if s == nil {
+		return
+	}
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.Flush(); err != nil {
+					logger.Warn("Failed to save persistent state", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+// writeAtomic replaces path with data: write and sync a temporary file in the
+// same directory, rename it over path, then sync the directory so the rename
+// itself survives a power loss.
+func writeAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary state file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to sync state file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write state file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace state file: %w", err)
+	}
+	if d, err := os.Open(dir); err == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package state
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	logger.SetDefaultLogger(&logger.Logger{Logger: zap.NewNop()})
+	os.Exit(m.Run())
+}
+
+type counters struct {
+	Requests int      `json:"requests"`
+	Models   []string `json:"models"`
+}
+
+func TestStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	s, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	live := counters{Requests: 3, Models: []string{"llama3"}}
+	Attach(s, "server", func(c counters) { t.Fatal("nothing saved yet") }, func() counters { return live })
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, FileName)); err != nil || info.Mode().Perm() != 0600 {
+		t.Fatalf("state file: %v, %v", info, err)
+	}
+
+	// Unchanged state is not rewritten.
+	before, _ := os.Stat(filepath.Join(dir, FileName))
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	after, _ := os.Stat(filepath.Join(dir, FileName))
+	if !after.ModTime().Equal(before.ModTime()) {
+		t.Fatal("unchanged state was rewritten")
+	}
+
+	reopened, err := Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var restored counters
+	Attach(reopened, "server", func(c counters) { restored = c }, func() counters { return restored })
+	if !reflect.DeepEqual(restored, live) {
+		t.Fatalf("restored %+v, want %+v", restored, live)
+	}
+}
+
+func TestStoreKeepsUnregisteredSections(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := Open(dir)
+	s.Register("tunnel", func() any { return map[string]string{"hostname": "a.example.com"} })
+	s.Register("energy", func() any { return 42 })
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A run without the tunnel subsystem must not drop its section.
+	s, _ = Open(dir)
+	s.Register("energy", func() any { return 43 })
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	s, _ = Open(dir)
+	var tunnel map[string]string
+	var joules int
+	if ok, err := s.Load("tunnel", &tunnel); !ok || err != nil || tunnel["hostname"] != "a.example.com" {
+		t.Fatalf("tunnel = %v, %v, %v", tunnel, ok, err)
+	}
+	if ok, _ := s.Load("energy", &joules); !ok || joules != 43 {
+		t.Fatalf("energy = %v", joules)
+	}
+}
+
+func TestStoreSetsAsideUnusableFiles(t *testing.T) {
+	cases := map[string]struct {
+		content string
+		aside   string
+	}{
+		"torn write":    {`{"version": 1, "sections": {"ser`, FileName + ".corrupt"},
+		"newer version": {`{"version": 99, "sections": {}}`, FileName + ".v99"},
+	}
+	for name, c := range cases {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, FileName), []byte(c.content), 0600)
+
+		s, err := Open(dir)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if ok, _ := s.Load("server", &counters{}); ok {
+			t.Errorf("%s: loaded a section", name)
+		}
+		if _, err := os.Stat(filepath.Join(dir, c.aside)); err != nil {
+			t.Errorf("%s: not set aside: %v", name, err)
+		}
+	}
+}
+
+func TestStoreDropsUndecodableSection(t *testing.T) {
+	dir := t.TempDir()
+	data, _ := json.Marshal(file{Version: Version, Sections: map[string]json.RawMessage{"server": json.RawMessage(`"not an object"`)}})
+	os.WriteFile(filepath.Join(dir, FileName), data, 0600)
+
+	s, _ := Open(dir)
+	Attach(s, "server", func(c counters) { t.Fatal("restored an undecodable section") }, func() counters { return counters{} })
+}
+
+func TestNilStore(t *testing.T) {
+	var s *Store
+	Attach(s, "server", func(c counters) {}, func() counters { return counters{} })
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -61,3 +61,23 @@ func ApprovalConsole(status string) (label, color string) {
 		return status, "\033[0m"
 	}
 }
+
+// VerificationReason describes why a local verification check failed.
+func VerificationReason(reason string) string {
+	switch reason {
+	case verify.ReasonManifestMissing:
+		return "Ollama manifest not found on disk"
+	case verify.ReasonManifestMismatch:
+		return "Ollama manifest does not match its digest"
+	case verify.ReasonBlobMissing:
+		return "model blob missing"
+	case verify.ReasonBlobMismatch:
+		return "model blob modified on disk"
+	case verify.ReasonIncompleteShards:
+		return "incomplete sharded checkpoint"
+	case verify.ReasonAttestationMismatch:
+		return "served model does not match its weights"
+	default:
+		return reason
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/verify"
 )
 
 func TestConsoleLLMUnreachable(t *testing.T) {
@@ -33,3 +34,12 @@ func TestConsoleUnknown(t *testing.T) {
 		t.Fatalf("got %q, want %q", got, want)
 	}
 }
+
+func TestVerificationReason(t *testing.T) {
+	if got := VerificationReason(verify.ReasonBlobMismatch); got != "model blob modified on disk" {
+		t.Fatalf("got %q", got)
+	}
+	if got := VerificationReason("new_reason"); got != "new_reason" {
+		t.Fatalf("unknown reasons pass through, got %q", got)
+	}
+}
@@ -0,0 +1,275 @@
+package verify
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+// ReasonAttestationMismatch is the failure reason when a canary's output
+// differs from the expected hash.
+const ReasonAttestationMismatch = "attestation_mismatch"
+
+// DefaultAttestInterval is how long canary outputs are reused before the
+// canaries are run again. Running them loads the model, which Ollama may have
+// to swap in.
+const DefaultAttestInterval = time.Hour
+
+// attestMismatchTTL is how long a mismatch is reused at most, so a backend
+// that served the wrong model for a while is checked again well before the
+// attest interval is up.
+const attestMismatchTTL = 5 * time.Minute
+
+// defaultCanaryMaxTokens caps canary output when a canary does not set it.
+const defaultCanaryMaxTokens = 32
+
+// Canary is a deterministic prompt run against the served model at
+// temperature 0. The platform supplies canaries in catalog entries; more can
+// be configured locally under verification.canaries.
+type Canary struct {
+	ID           string `json:"id" yaml:"id"`
+	Model        string `json:"-" yaml:"model"` // local canaries only; empty = every model
+	Prompt       string `json:"prompt" yaml:"prompt"`
+	MaxTokens    int    `json:"max_tokens,omitempty" yaml:"max_tokens"`
+	OutputSHA256 string `json:"output_sha256,omitempty" yaml:"output_sha256"` // expected; empty = judged by the platform
+}
+
+// Attestation is the hashed output of one canary, submitted with the
+// verification measurements.
+type Attestation struct {
+	CanaryID     string `json:"canary_id"`
+	PromptSHA256 string `json:"prompt_sha256"`
+	OutputSHA256 string `json:"output_sha256"`
+	ServedModel  string `json:"served_model,omitempty"` // model name the backend answered as
+}
+
+// AttestationError is a canary whose output did not match its expected hash.
+type AttestationError struct {
+	Model    string
+	CanaryID string
+	Want     string
+	Got      string
+}
+
+func (e *AttestationError) Error() string {
+	return fmt.Sprintf("model %s: canary %s output hashes to %s, expected %s", e.Model, e.CanaryID, e.Got, e.Want)
+}
+
+// validate checks a locally configured canary.
+func (c Canary) validate() error {
+	if strings.TrimSpace(c.Prompt) == "" {
+		return fmt.Errorf("canary %q: prompt is required", c.ID)
+	}
+	if c.MaxTokens < 0 {
+		return fmt.Errorf("canary %q: max_tokens must not be negative", c.ID)
+	}
+	if c.OutputSHA256 != "" {
+		if b, err := hex.DecodeString(c.OutputSHA256); err != nil || len(b) != 32 {
+			return fmt.Errorf("canary %q: output_sha256 must be 64 hex characters", c.ID)
+		}
+	}
+	return nil
+}
+
+// matches reports whether a's output is the one c expects; canaries without
+// an expected hash are judged by the platform.
+func (c Canary) matches(a Attestation) bool {
+	return c.OutputSHA256 == "" || strings.EqualFold(a.OutputSHA256, c.OutputSHA256)
+}
+
+// attestEntry is a model's last canary outputs, or the mismatch they showed.
+type attestEntry struct {
+	key          string // canary set and model digest they were taken for
+	at           time.Time
+	attestations []Attestation
+	mismatch     *AttestationError
+}
+
+// EnableAttestation runs canary prompts through llmClient whenever a model is
+// verified and submits their output hashes. local adds canaries to those in
+// the catalog; outputs are reused for interval (0 = DefaultAttestInterval).
+func (v *Verifier) EnableAttestation(llmClient llm.Client, local []Canary, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultAttestInterval
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.attestClient = llmClient
+	v.localCanaries = append([]Canary(nil), local...)
+	v.attestInterval = interval
+}
+
+// canaries are the catalog's canaries for alias followed by matching local ones.
+func (v *Verifier) canaries(alias string, entry CatalogEntry) []Canary {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	out := append([]Canary(nil), entry.Canaries...)
+	for _, c := range v.localCanaries {
+		if c.Model != "" && c.Model != alias {
+			continue
+		}
+		if c.ID == "" {
+			c.ID = "local-" + sha256Bytes([]byte(c.Prompt))[:12]
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// attest runs alias's canaries, or reuses outputs from the last interval for
+// the same canaries and digest. It returns nil without attestation enabled or
+// canaries. An output that differs from a canary's expected hash is an
+// *AttestationError.
+func (v *Verifier) attest(ctx context.Context, alias, digest string, entry CatalogEntry) ([]Attestation, error) {
+	v.mu.Lock()
+	client, interval := v.attestClient, v.attestInterval
+	v.mu.Unlock()
+	if client == nil {
+		return nil, nil
+	}
+	canaries := v.canaries(alias, entry)
+	if len(canaries) == 0 {
+		return nil, nil
+	}
+
+	key := canarySetKey(canaries) + "\n" + digest
+	v.mu.Lock()
+	cached := v.attestCache[alias]
+	v.mu.Unlock()
+	if cached != nil && cached.key == key {
+		ttl := interval
+		if cached.mismatch != nil {
+			ttl = min(interval, attestMismatchTTL)
+		}
+		if time.Since(cached.at) < ttl {
+			if cached.mismatch != nil {
+				return nil, cached.mismatch
+			}
+			return cached.attestations, nil
+		}
+	}
+
+	// A backend that could not run a canary is retried on the next
+	// verification; nothing is cached.
+	next := &attestEntry{key: key}
+	for _, c := range canaries {
+		a, err := runCanary(ctx, client, alias, c)
+		if err == nil && !c.matches(a) {
+			// Run it once more before failing the model, so one odd reply
+			// (e.g. while the backend reloads) is not taken as a swap.
+			a, err = runCanary(ctx, client, alias, c)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("canary %s: %w", c.ID, err)
+		}
+		if !c.matches(a) {
+			next.mismatch = &AttestationError{Model: alias, CanaryID: c.ID, Want: strings.ToLower(c.OutputSHA256), Got: a.OutputSHA256}
+			break
+		}
+		next.attestations = append(next.attestations, a)
+	}
+
+	next.at = time.Now()
+	v.mu.Lock()
+	v.attestCache[alias] = next
+	v.mu.Unlock()
+	if next.mismatch != nil {
+		return nil, next.mismatch
+	}
+	return next.attestations, nil
+}
+
+// attestFailure sets res's status and reason for an error from attest. Only a
+// mismatch fails the model; a canary that could not be run leaves it pending.
+func attestFailure(res *Result, err error) {
+	var attestErr *AttestationError
+	if errors.As(err, &attestErr) {
+		res.Status = StatusFailed
+		res.Reason = ReasonAttestationMismatch
+		return
+	}
+	res.Status = StatusPending
+}
+
+// canaryRequest is an OpenAI chat completion pinned for reproducible output.
+type canaryRequest struct {
+	Model       string            `json:"model"`
+	Messages    []llm.ChatMessage `json:"messages"`
+	Temperature float64           `json:"temperature"`
+	TopP        float64           `json:"top_p"`
+	Seed        int               `json:"seed"`
+	MaxTokens   int               `json:"max_tokens"`
+	Stream      bool              `json:"stream"`
+}
+
+type canaryResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message struct {
+			Content string `json:"content"`
+		} `json:"message"`
+	} `json:"choices"`
+}
+
+// runCanary sends c through the same forwarding path as consumer requests and
+// hashes the reply with surrounding whitespace trimmed.
+func runCanary(ctx context.Context, client llm.Client, alias string, c Canary) (Attestation, error) {
+	maxTokens := c.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = defaultCanaryMaxTokens
+	}
+	body, err := json.Marshal(canaryRequest{
+		Model:     alias,
+		Messages:  []llm.ChatMessage{{Role: "user", Content: c.Prompt}},
+		TopP:      1,
+		MaxTokens: maxTokens,
+	})
+	if err != nil {
+		return Attestation{}, err
+	}
+	raw, err := client.ForwardRequest(ctx, "/v1/chat/completions", body)
+	if err != nil {
+		return Attestation{}, err
+	}
+	var resp canaryResponse
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return Attestation{}, fmt.Errorf("parse response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return Attestation{}, fmt.Errorf("response has no choices")
+	}
+	return Attestation{
+		CanaryID:     c.ID,
+		PromptSHA256: sha256Bytes([]byte(c.Prompt)),
+		OutputSHA256: sha256Bytes([]byte(strings.TrimSpace(resp.Choices[0].Message.Content))),
+		ServedModel:  resp.Model,
+	}, nil
+}
+
+// canarySetKey identifies a set of canaries, so changed canaries are rerun.
+func canarySetKey(canaries []Canary) string {
+	parts := make([]string, 0, len(canaries))
+	for _, c := range canaries {
+		parts = append(parts, fmt.Sprintf("%s\x00%s\x00%d\x00%s", c.ID, c.Prompt, c.MaxTokens, c.OutputSHA256))
+	}
+	sort.Strings(parts)
+	return sha256Bytes([]byte(strings.Join(parts, "\x01")))
+}
+
+// measurementKey identifies a set of weight measurements, so new weights are
+// attested again.
+func measurementKey(files []FileMeasurement) string {
+	parts := make([]string, 0, len(files))
+	for _, f := range files {
+		parts = append(parts, f.Name+"\x00"+f.Hash)
+	}
+	sort.Strings(parts)
+	return sha256Bytes([]byte(strings.Join(parts, "\x01")))
+}
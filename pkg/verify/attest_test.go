@@ -0,0 +1,173 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+// canaryBackend answers chat completions with a reply per prompt. A reply in
+// once is given a single time before replies; err fails every request.
+type canaryBackend struct {
+	llm.Client
+	replies  map[string]string
+	once     map[string]string
+	err      error
+	requests []map[string]any
+}
+
+func (b *canaryBackend) ForwardRequest(ctx context.Context, path string, body []byte) ([]byte, error) {
+	var req map[string]any
+	json.Unmarshal(body, &req)
+	b.requests = append(b.requests, req)
+	if b.err != nil {
+		return nil, b.err
+	}
+	prompt := req["messages"].([]any)[0].(map[string]any)["content"].(string)
+	content, ok := b.once[prompt]
+	if ok {
+		delete(b.once, prompt)
+	} else {
+		content = b.replies[prompt]
+	}
+	reply, _ := json.Marshal(map[string]any{
+		"model":   req["model"],
+		"choices": []any{map[string]any{"message": map[string]any{"role": "assistant", "content": "  " + content + "\n"}}},
+	})
+	return reply, nil
+}
+
+// attestSetup returns a vLLM verifier for org/model with one catalog canary,
+// and the requests the platform received.
+func attestSetup(t *testing.T, platformReply string) (*Verifier, *canaryBackend, *[]verifyModelRequest) {
+	t.Helper()
+	var submitted []verifyModelRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req verifyModelRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		submitted = append(submitted, req)
+		w.Write([]byte(platformReply))
+	}))
+	t.Cleanup(ts.Close)
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"config.json": "{}", "model.bin": "weights"})
+	catalog := &Catalog{entries: map[string]CatalogEntry{"org/model": {
+		ID:       "1",
+		Canaries: []Canary{{ID: "capital", Prompt: "Capital of France?"}},
+	}}}
+	v := NewVerifier(catalog, NewServerClient(ts.URL, "k"), "vllm", "", root)
+	backend := &canaryBackend{replies: map[string]string{"Capital of France?": "Paris", "2+2?": "4"}}
+	return v, backend, &submitted
+}
+
+func TestAttestationSubmitsCanaryHashes(t *testing.T) {
+	v, backend, submitted := attestSetup(t, `{"verification_status":"verified"}`)
+	v.EnableAttestation(backend, []Canary{
+		{Prompt: "2+2?", MaxTokens: 4, OutputSHA256: sha256Bytes([]byte("4"))},
+		{Model: "other/model", Prompt: "not for this model"},
+	}, 0)
+
+	res, err := v.VerifyVLLMModel(context.Background(), "org/model")
+	if err != nil || res.Status != StatusVerified {
+		t.Fatalf("verify: %+v, %v", res, err)
+	}
+	if len(*submitted) != 1 || len((*submitted)[0].Attestations) != 2 {
+		t.Fatalf("submitted %+v, want two attestations", *submitted)
+	}
+	got := (*submitted)[0].Attestations
+	if got[0].CanaryID != "capital" || got[0].OutputSHA256 != sha256Bytes([]byte("Paris")) || got[0].ServedModel != "org/model" {
+		t.Errorf("catalog canary: %+v", got[0])
+	}
+	if !strings.HasPrefix(got[1].CanaryID, "local-") || got[1].PromptSHA256 != sha256Bytes([]byte("2+2?")) {
+		t.Errorf("local canary: %+v", got[1])
+	}
+	for _, req := range backend.requests {
+		if req["temperature"] != float64(0) || req["seed"] != float64(0) || req["stream"] != false {
+			t.Errorf("canary request not deterministic: %v", req)
+		}
+	}
+	if backend.requests[0]["max_tokens"] != float64(defaultCanaryMaxTokens) || backend.requests[1]["max_tokens"] != float64(4) {
+		t.Errorf("max_tokens: %v, %v", backend.requests[0]["max_tokens"], backend.requests[1]["max_tokens"])
+	}
+}
+
+func TestAttestationMismatchFailsLocally(t *testing.T) {
+	v, backend, submitted := attestSetup(t, `{"verification_status":"verified"}`)
+	v.EnableAttestation(backend, []Canary{{ID: "sum", Prompt: "2+2?", OutputSHA256: sha256Bytes([]byte("5"))}}, 0)
+
+	for i := 0; i < 2; i++ {
+		res, err := v.VerifyVLLMModel(context.Background(), "org/model")
+		var attestErr *AttestationError
+		if !errors.As(err, &attestErr) || res.Status != StatusFailed || res.Reason != ReasonAttestationMismatch {
+			t.Fatalf("run %d: %+v, %v; want failed with %s", i, res, err, ReasonAttestationMismatch)
+		}
+	}
+	if len(*submitted) != 0 {
+		t.Fatal("mismatching model submitted to the platform")
+	}
+	// The catalog canary, then the mismatching one twice; the second
+	// verification reuses the mismatch.
+	if len(backend.requests) != 3 {
+		t.Fatalf("%d canary requests; a mismatch is re-run once, then reused", len(backend.requests))
+	}
+
+	// A mismatch is not kept for the whole attest interval
+	v.attestCache["org/model"].at = time.Now().Add(-attestMismatchTTL)
+	v.VerifyVLLMModel(context.Background(), "org/model")
+	if len(backend.requests) != 6 {
+		t.Fatalf("%d canary requests; an expired mismatch must be checked again", len(backend.requests))
+	}
+}
+
+func TestAttestationRerunsMismatchAndRetriesErrors(t *testing.T) {
+	v, backend, submitted := attestSetup(t, `{"verification_status":"verified"}`)
+	backend.once = map[string]string{"Capital of France?": "Lyon"}
+	v.EnableAttestation(backend, []Canary{{ID: "capital", Prompt: "Capital of France?", OutputSHA256: sha256Bytes([]byte("Paris"))}}, 0)
+
+	// One odd reply is re-run and passes
+	res, err := v.VerifyVLLMModel(context.Background(), "org/model")
+	if err != nil || res.Status != StatusVerified || len(*submitted) != 1 {
+		t.Fatalf("flaky canary: %+v, %v, %d submitted", res, err, len(*submitted))
+	}
+
+	// A backend that cannot run canaries leaves the model pending, not failed
+	v.attestCache = make(map[string]*attestEntry)
+	v.resultCache = make(map[string]*verifyResultEntry)
+	backend.err = errors.New("connection refused")
+	res, err = v.VerifyVLLMModel(context.Background(), "org/model")
+	if err == nil || res.Status != StatusPending || res.Reason != "" {
+		t.Fatalf("unreachable backend: %+v, %v; want pending without a reason", res, err)
+	}
+}
+
+func TestPlatformAttestationMismatchDowngrades(t *testing.T) {
+	v, backend, _ := attestSetup(t, `{"verification_status":"verified","attestation":"mismatch"}`)
+	v.EnableAttestation(backend, nil, 0)
+
+	res, err := v.VerifyVLLMModel(context.Background(), "org/model")
+	if err != nil || res.Status != StatusFailed || res.Reason != ReasonAttestationMismatch {
+		t.Fatalf("verify: %+v, %v; want failed with %s", res, err, ReasonAttestationMismatch)
+	}
+}
+
+func TestConfigValidateCanaries(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Canaries = []Canary{{Prompt: "hi", OutputSHA256: sha256Bytes([]byte("x"))}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid canary: %v", err)
+	}
+	for _, c := range []Canary{{Prompt: " "}, {Prompt: "hi", OutputSHA256: "abc"}, {Prompt: "hi", MaxTokens: -1}} {
+		cfg.Canaries = []Canary{c}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("%+v accepted", c)
+		}
+	}
+}
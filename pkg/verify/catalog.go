@@ -19,7 +19,7 @@ type Catalog struct {
 	client      *http.Client
 
 	mu      sync.RWMutex
-	entries map[string]CatalogEntry // alias -> entry
+	entries map[string]CatalogEntry // alias -> entry; nil until the first successful refresh
 }
 
 // NewCatalog creates a catalog client.
@@ -28,7 +28,6 @@ func NewCatalog(baseURL, serviceType string) *Catalog {
 		baseURL:     stringsTrimRightSlash(baseURL),
 		serviceType: serviceType,
 		client:      &http.Client{Timeout: 30 * time.Second},
-		entries:     make(map[string]CatalogEntry),
 	}
 }
 
@@ -77,6 +76,14 @@ func (c *Catalog) Get(alias string) (CatalogEntry, bool) {
 	return entry, ok
 }
 
+// Loaded reports whether the catalog has been fetched. Until then a model
+// missing from it may still be approved.
+func (c *Catalog) Loaded() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.entries != nil
+}
+
 // Aliases returns approved aliases in the catalog.
 func (c *Catalog) Aliases() []string {
 	c.mu.RLock()
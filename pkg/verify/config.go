@@ -0,0 +1,52 @@
+package verify
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultPollInterval is how often watched model directories are rescanned
+// where inotify is unavailable.
+const DefaultPollInterval = 10 * time.Second
+
+// DefaultRefreshInterval is how often the background worker re-verifies all
+// local models to keep the inference allowlist current.
+const DefaultRefreshInterval = time.Minute
+
+// Config tunes weight verification.
+type Config struct {
+	HashWorkers     int           `yaml:"hash_workers"`     // max files hashed in parallel; 0 = min(CPUs, 8)
+	Watch           bool          `yaml:"watch"`            // re-verify as soon as model files change on disk
+	PollInterval    time.Duration `yaml:"poll_interval"`    // rescan interval when inotify is unavailable
+	RefreshInterval time.Duration `yaml:"refresh_interval"` // background re-verification of all local models
+	Canaries        []Canary      `yaml:"canaries"`         // attestation prompts added to the catalog's
+	AttestInterval  time.Duration `yaml:"attest_interval"`  // how long canary outputs are reused
+}
+
+// DefaultConfig watches model files, polling every DefaultPollInterval where
+// needed, refreshes the allowlist every DefaultRefreshInterval and reuses
+// canary outputs for DefaultAttestInterval.
+func DefaultConfig() Config {
+	return Config{
+		Watch:           true,
+		PollInterval:    DefaultPollInterval,
+		RefreshInterval: DefaultRefreshInterval,
+		AttestInterval:  DefaultAttestInterval,
+	}
+}
+
+// Validate checks the config for values that cannot work.
+func (c Config) Validate() error {
+	if c.HashWorkers < 0 {
+		return fmt.Errorf("hash_workers must not be negative")
+	}
+	if c.PollInterval < 0 || c.RefreshInterval < 0 || c.AttestInterval < 0 {
+		return fmt.Errorf("intervals must not be negative")
+	}
+	for _, canary := range c.Canaries {
+		if err := canary.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
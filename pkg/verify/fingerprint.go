@@ -38,9 +38,17 @@ func fullFileSHA256(path string) (string, error) {
 		return "", err
 	}
 	defer f.Close()
+	return sha256Hex(f)
+}
+
+func sha256Bytes(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
 
+func sha256Hex(r io.Reader) (string, error) {
 	h := sha256.New()
-	if _, err := io.Copy(h, f); err != nil {
+	if _, err := io.Copy(h, r); err != nil {
 		return "", err
 	}
 	return hex.EncodeToString(h.Sum(nil)), nil
@@ -78,7 +86,7 @@ func WeightFingerprint(root string, manifest []ManifestEntry) (string, error) {
 
 	lines := make([]string, 0, len(manifest))
 	for _, entry := range manifest {
-		path := filepath.Join(root, entry.Name)
+		path := filepath.Join(root, filepath.FromSlash(entry.Name))
 		hash, err := FileHash(path, entry.HashMethod)
 		if err != nil {
 			return "", fmt.Errorf("hash %s: %w", entry.Name, err)
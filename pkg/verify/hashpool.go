@@ -0,0 +1,223 @@
+package verify
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"time"
+)
+
+const (
+	// maxDefaultHashWorkers caps the automatic worker count; more parallel
+	// reads than this rarely help even on fast NVMe arrays.
+	maxDefaultHashWorkers = 8
+	// initialHashWorkers is where the pool starts before probing throughput.
+	initialHashWorkers = 2
+	// hashProbeInterval is how often the pool compares throughput to decide
+	// whether another worker helps.
+	hashProbeInterval = time.Second
+)
+
+// DefaultHashWorkers is the hashing concurrency limit when none is configured.
+func DefaultHashWorkers() int {
+	return min(runtime.NumCPU(), maxDefaultHashWorkers)
+}
+
+// Progress is how far a model's weight measurement has got. Files already
+// hashed at an unchanged stat count as done from the start; bytes count only
+// the data that has to be read.
+type Progress struct {
+	Alias      string    `json:"alias"`
+	FilesDone  int       `json:"files_done"`
+	FilesTotal int       `json:"files_total"`
+	BytesDone  int64     `json:"bytes_done"`
+	BytesTotal int64     `json:"bytes_total"`
+	Workers    int       `json:"workers"`
+	StartedAt  time.Time `json:"started_at"`
+}
+
+// Percent is the share of bytes read, or of files when nothing is read in full.
+func (p Progress) Percent() float64 {
+	if p.BytesTotal > 0 {
+		return float64(p.BytesDone) / float64(p.BytesTotal) * 100
+	}
+	if p.FilesTotal > 0 {
+		return float64(p.FilesDone) / float64(p.FilesTotal) * 100
+	}
+	return 0
+}
+
+// String renders progress for the console, e.g. "verifying 3/8 files, 42%".
+func (p Progress) String() string {
+	return fmt.Sprintf("verifying %d/%d files, %.0f%%", p.FilesDone, p.FilesTotal, p.Percent())
+}
+
+// progressTracker is one measurement's live progress, updated by the workers.
+type progressTracker struct {
+	mu sync.Mutex
+	p  Progress
+}
+
+func (t *progressTracker) snapshot() Progress {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.p
+}
+
+func (t *progressTracker) addBytes(n int64) {
+	t.mu.Lock()
+	t.p.BytesDone += n
+	t.mu.Unlock()
+}
+
+func (t *progressTracker) fileDone() {
+	t.mu.Lock()
+	t.p.FilesDone++
+	t.mu.Unlock()
+}
+
+func (t *progressTracker) setWorkers(n int) {
+	t.mu.Lock()
+	t.p.Workers = n
+	t.mu.Unlock()
+}
+
+// hashJob is one file to hash; index is its position in the measurement.
+type hashJob struct {
+	index  int
+	name   string
+	path   string
+	method string
+	stat   fileStat
+}
+
+// hashFiles hashes jobs on a bounded pool. It starts with initialHashWorkers
+// and adds one worker at a time while total read throughput keeps rising by
+// at least 10%, backing off by one when it drops, so a spinning disk is not
+// thrashed by parallel reads while NVMe gets its queue depth. Results are in
+// job order. The first error stops new work and is returned once running
+// hashes finish.
+func hashFiles(jobs []hashJob, maxWorkers int, tracker *progressTracker) ([]FileMeasurement, error) {
+	out := make([]FileMeasurement, len(jobs))
+	if len(jobs) == 0 {
+		return out, nil
+	}
+	if maxWorkers <= 0 {
+		maxWorkers = DefaultHashWorkers()
+	}
+	maxWorkers = min(maxWorkers, len(jobs))
+
+	var (
+		mu       sync.Mutex
+		cond     = sync.NewCond(&mu)
+		limit    = min(initialHashWorkers, maxWorkers)
+		active   int
+		next     int
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	tracker.setWorkers(limit)
+
+	// Adjust the limit from observed throughput until every job has started.
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(hashProbeInterval)
+		defer ticker.Stop()
+		var lastBytes int64
+		var best float64
+		for {
+			select {
+			case <-ticker.C:
+			case <-stop:
+				return
+			}
+			done := tracker.snapshot().BytesDone
+			rate := float64(done - lastBytes)
+			lastBytes = done
+
+			mu.Lock()
+			switch {
+			case rate > best*1.1 && limit < maxWorkers:
+				best = rate
+				limit++
+				cond.Broadcast()
+			case rate > best:
+				best = rate
+			case rate < best*0.8 && limit > 1:
+				limit--
+			}
+			tracker.setWorkers(limit)
+			mu.Unlock()
+		}
+	}()
+
+	for w := 0; w < maxWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				mu.Lock()
+				for active >= limit && next < len(jobs) && firstErr == nil {
+					cond.Wait()
+				}
+				if next >= len(jobs) || firstErr != nil {
+					mu.Unlock()
+					return
+				}
+				i, job := next, jobs[next]
+				next++
+				active++
+				mu.Unlock()
+
+				hash, err := hashCounting(job.path, job.method, tracker)
+
+				mu.Lock()
+				active--
+				if err != nil && firstErr == nil {
+					firstErr = fmt.Errorf("hash %s: %w", job.name, err)
+				}
+				cond.Broadcast()
+				mu.Unlock()
+				if err != nil {
+					continue
+				}
+				out[i] = FileMeasurement{
+					Name:       job.name,
+					Hash:       hash,
+					HashMethod: job.method,
+					Size:       job.stat.size,
+				}
+				tracker.fileDone()
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	return out, firstErr
+}
+
+// hashCounting is FileHash reporting bytes read to tracker as it goes.
+func hashCounting(path, method string, tracker *progressTracker) (string, error) {
+	if method != "full" {
+		return FileHash(path, method)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return sha256Hex(&countingReader{r: f, tracker: tracker})
+}
+
+type countingReader struct {
+	r       io.Reader
+	tracker *progressTracker
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.tracker.addBytes(int64(n))
+	return n, err
+}
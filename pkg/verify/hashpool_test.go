@@ -0,0 +1,78 @@
+package verify
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHashFilesKeepsOrderAndCountsProgress(t *testing.T) {
+	root := t.TempDir()
+	for i := 0; i < 6; i++ {
+		name := fmt.Sprintf("shard-%d.bin", i)
+		if err := os.WriteFile(filepath.Join(root, name), []byte(strings.Repeat("w", 100*(i+1))), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, err := weightDirStats(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// One file is already known at its current stat and must not be reread.
+	reused := filepath.Join(root, "shard-0.bin")
+	known := map[string]cachedHash{reused: {stat: stats["shard-0.bin"], file: FileMeasurement{Name: "shard-0.bin", Hash: "from-cache"}}}
+
+	tracker := &progressTracker{p: Progress{Alias: "m"}}
+	files, hashed, err := measureWeightDir(root, stats, known, 4, tracker)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, f := range files {
+		want := fmt.Sprintf("shard-%d.bin", i)
+		if f.Name != want {
+			t.Fatalf("files[%d] = %s, want %s", i, f.Name, want)
+		}
+		if i == 0 {
+			continue
+		}
+		if sum, _ := fullFileSHA256(filepath.Join(root, want)); f.Hash != sum {
+			t.Fatalf("%s hash = %s, want %s", want, f.Hash, sum)
+		}
+	}
+	if files[0].Hash != "from-cache" || len(hashed) != 5 {
+		t.Fatalf("reused %q, hashed %d files; want the cached hash and 5 rehashed", files[0].Hash, len(hashed))
+	}
+
+	p := tracker.snapshot()
+	if p.FilesDone != 6 || p.FilesTotal != 6 || p.BytesDone != p.BytesTotal || p.BytesTotal != 2000 {
+		t.Fatalf("progress = %+v", p)
+	}
+	if got := p.String(); got != "verifying 6/6 files, 100%" {
+		t.Fatalf("progress string = %q", got)
+	}
+}
+
+func TestHashFilesReportsFirstError(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.bin"), []byte("a"), 0644)
+	jobs := []hashJob{
+		{name: "a.bin", path: filepath.Join(root, "a.bin"), method: "full"},
+		{name: "gone.bin", path: filepath.Join(root, "gone.bin"), method: "full"},
+	}
+	if _, err := hashFiles(jobs, 1, &progressTracker{}); err == nil || !strings.Contains(err.Error(), "gone.bin") {
+		t.Fatalf("err = %v, want the missing file named", err)
+	}
+}
+
+func TestProgressPercent(t *testing.T) {
+	if got := (Progress{FilesDone: 3, FilesTotal: 8, BytesDone: 42, BytesTotal: 100}).String(); got != "verifying 3/8 files, 42%" {
+		t.Fatalf("by bytes: %q", got)
+	}
+	// Header-only hashes read no tracked bytes, so files drive the percentage.
+	if got := (Progress{FilesDone: 1, FilesTotal: 4}).Percent(); got != 25 {
+		t.Fatalf("by files: %v", got)
+	}
+}
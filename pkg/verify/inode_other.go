@@ -0,0 +1,10 @@
+//go:build !unix
+
+package verify
+
+import "os"
+
+// fileInode is unsupported here; size and mtime alone identify a file version.
+func fileInode(info os.FileInfo) uint64 {
+	return 0
+}
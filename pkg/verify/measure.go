@@ -2,48 +2,61 @@ package verify
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
-// measureWeightDir hashes top-level files in a vLLM weight directory.
-func measureWeightDir(root string) ([]FileMeasurement, error) {
-	entries, err := os.ReadDir(root)
-	if err != nil {
-		return nil, fmt.Errorf("read weight dir: %w", err)
+// measureWeightDir measures the files in stats (every file under a vLLM
+// weight directory by relative path, from weightDirStats). A file whose path and stat match
+// its entry in known reuses that measurement; the rest are hashed on a pool
+// of up to workers goroutines, and returned as hashed so the caller can cache
+// them. known is only read.
+func measureWeightDir(root string, stats map[string]fileStat, known map[string]cachedHash, workers int, tracker *progressTracker) (files []FileMeasurement, hashed map[string]cachedHash, err error) {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if len(names) == 0 {
+		return nil, nil, fmt.Errorf("no weight files in %s", root)
 	}
 
-	var files []FileMeasurement
-	for _, entry := range entries {
-		if entry.IsDir() {
+	files = make([]FileMeasurement, len(names))
+	var jobs []hashJob
+	var bytesTotal int64
+	reused := 0
+	for i, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		stat := stats[name]
+		if cached, ok := known[path]; ok && cached.stat == stat {
+			files[i] = cached.file
+			reused++
 			continue
 		}
-		name := entry.Name()
-		path := filepath.Join(root, name)
 		method := "full"
 		if strings.HasSuffix(name, ".safetensors") {
 			method = "safetensors_header"
+		} else {
+			bytesTotal += stat.size
 		}
-		info, err := entry.Info()
-		if err != nil {
-			return nil, err
-		}
-		hash, err := FileHash(path, method)
-		if err != nil {
-			return nil, fmt.Errorf("hash %s: %w", name, err)
-		}
-		files = append(files, FileMeasurement{
-			Name:       name,
-			Hash:       hash,
-			HashMethod: method,
-			Size:       info.Size(),
-		})
+		jobs = append(jobs, hashJob{index: i, name: name, path: path, method: method, stat: stat})
+	}
+
+	tracker.mu.Lock()
+	tracker.p.FilesTotal, tracker.p.FilesDone, tracker.p.BytesTotal = len(names), reused, bytesTotal
+	tracker.mu.Unlock()
+
+	measured, err := hashFiles(jobs, workers, tracker)
+	if err != nil {
+		return nil, nil, err
 	}
-	if len(files) == 0 {
-		return nil, fmt.Errorf("no weight files in %s", root)
+	hashed = make(map[string]cachedHash, len(jobs))
+	for i, job := range jobs {
+		files[job.index] = measured[i]
+		hashed[job.path] = cachedHash{stat: job.stat, file: measured[i]}
 	}
-	return files, nil
+	return files, hashed, nil
 }
 
 func hfRepoForCatalog(alias string, entry CatalogEntry) string {
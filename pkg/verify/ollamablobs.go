@@ -0,0 +1,155 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
+)
+
+// Failure reasons for models that fail local checks before reaching the platform.
+const (
+	ReasonManifestMissing  = "manifest_missing"  // no Ollama manifest for the model on disk
+	ReasonManifestMismatch = "manifest_mismatch" // manifest file does not hash to the digest Ollama reports
+	ReasonBlobMissing      = "blob_missing"      // a layer the manifest lists is not in the blob store
+	ReasonBlobMismatch     = "blob_mismatch"     // GGUF blob does not hash to its layer digest
+	ReasonIncompleteShards = "incomplete_shards" // sharded safetensors checkpoint missing or extra shards
+)
+
+const (
+	ollamaRegistry       = "registry.ollama.ai"
+	ollamaModelMediaType = "application/vnd.ollama.image.model"
+)
+
+// DefaultOllamaModels returns Ollama's models directory: $OLLAMA_MODELS, else
+// ~/.ollama/models, else /usr/share/ollama/.ollama/models where the Linux
+// service install keeps them.
+func DefaultOllamaModels() (string, error) {
+	if dir := strings.TrimSpace(os.Getenv("OLLAMA_MODELS")); dir != "" {
+		return dir, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ".ollama", "models")
+	if _, err := os.Stat(dir); err != nil {
+		if st, err := os.Stat("/usr/share/ollama/.ollama/models"); err == nil && st.IsDir() {
+			return "/usr/share/ollama/.ollama/models", nil
+		}
+	}
+	return dir, nil
+}
+
+// BlobError is a local Ollama check that failed; Reason is one of the Reason constants.
+type BlobError struct {
+	Model  string
+	Reason string
+	Path   string
+	Want   string // expected digest, when comparing
+	Got    string
+}
+
+func (e *BlobError) Error() string {
+	switch e.Reason {
+	case ReasonManifestMissing:
+		return fmt.Sprintf("ollama model %s: no manifest at %s", e.Model, e.Path)
+	case ReasonBlobMissing:
+		return fmt.Sprintf("ollama model %s: blob %s missing", e.Model, e.Path)
+	default:
+		return fmt.Sprintf("ollama model %s: %s hashes to %s, expected %s (%s)", e.Model, e.Path, e.Got, e.Want, e.Reason)
+	}
+}
+
+// ollamaManifest is the part of an Ollama image manifest naming its layers.
+type ollamaManifest struct {
+	Layers []ollamaLayer `json:"layers"`
+}
+
+type ollamaLayer struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// ollamaManifestPath maps a model name to its manifest file, e.g. llama3:8b to
+// manifests/registry.ollama.ai/library/llama3/8b and user/model to
+// manifests/registry.ollama.ai/user/model/latest.
+func ollamaManifestPath(modelsDir, name string) string {
+	repo, tag := name, "latest"
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		repo, tag = name[:i], name[i+1:]
+	}
+	parts := strings.Split(repo, "/")
+	switch len(parts) {
+	case 1:
+		parts = []string{ollamaRegistry, "library", parts[0]}
+	case 2:
+		parts = append([]string{ollamaRegistry}, parts...)
+	}
+	parts = append(parts, tag)
+	return filepath.Join(append([]string{modelsDir, "manifests"}, parts...)...)
+}
+
+// ollamaBlobPath is where Ollama stores the blob with digest "sha256:<hex>".
+func ollamaBlobPath(modelsDir, digest string) string {
+	return filepath.Join(modelsDir, "blobs", "sha256-"+NormalizeDigest(digest))
+}
+
+// readOllamaManifest loads name's manifest and checks that it hashes to the
+// digest /api/tags reported. It returns the model (GGUF) layer.
+func readOllamaManifest(modelsDir, name, digest string) (ollamaLayer, error) {
+	path := ollamaManifestPath(modelsDir, name)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return ollamaLayer{}, &BlobError{Model: name, Reason: ReasonManifestMissing, Path: path}
+	}
+	if err != nil {
+		return ollamaLayer{}, err
+	}
+	if got := sha256Bytes(data); got != NormalizeDigest(digest) {
+		return ollamaLayer{}, &BlobError{Model: name, Reason: ReasonManifestMismatch, Path: path, Want: NormalizeDigest(digest), Got: got}
+	}
+
+	return ollamaModelLayer(name, path, data)
+}
+
+func ollamaModelLayer(name, path string, manifest []byte) (ollamaLayer, error) {
+	var m ollamaManifest
+	if err := json.Unmarshal(manifest, &m); err != nil {
+		return ollamaLayer{}, fmt.Errorf("parse %s: %w", path, err)
+	}
+	for _, layer := range m.Layers {
+		if layer.MediaType == ollamaModelMediaType {
+			return layer, nil
+		}
+	}
+	return ollamaLayer{}, fmt.Errorf("ollama model %s: manifest has no model layer", name)
+}
+
+// LocalGGUF reads the GGUF header of an installed Ollama model, by alias
+// (gguf/llama3:8b) or name. Unlike verification it does not hash anything.
+func LocalGGUF(modelsDir, alias string) (gguf.Metadata, error) {
+	name := ollamaModelName(alias)
+	path := ollamaManifestPath(modelsDir, name)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return gguf.Metadata{}, err
+	}
+	layer, err := ollamaModelLayer(name, path, data)
+	if err != nil {
+		return gguf.Metadata{}, err
+	}
+	return gguf.ReadFile(ollamaBlobPath(modelsDir, layer.Digest))
+}
+
+// ollamaModelName is the Ollama model name in an alias such as gguf/llama3:8b.
+func ollamaModelName(alias string) string {
+	if _, name, ok := strings.Cut(alias, "/"); ok {
+		return name
+	}
+	return alias
+}
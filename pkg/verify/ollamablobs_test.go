@@ -0,0 +1,129 @@
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOllamaManifestPath(t *testing.T) {
+	cases := map[string]string{
+		"llama3:8b":                "manifests/registry.ollama.ai/library/llama3/8b",
+		"llama3":                   "manifests/registry.ollama.ai/library/llama3/latest",
+		"user/model:q4":            "manifests/registry.ollama.ai/user/model/q4",
+		"hf.co/org/repo:Q4_K_M":    "manifests/hf.co/org/repo/Q4_K_M",
+		"localhost:5000/ns/m":      "manifests/localhost:5000/ns/m/latest",
+		"localhost:5000/ns/m:v1.2": "manifests/localhost:5000/ns/m/v1.2",
+	}
+	for name, want := range cases {
+		if got := ollamaManifestPath("/models", name); got != filepath.Join("/models", filepath.FromSlash(want)) {
+			t.Errorf("%s: %s, want %s", name, got, want)
+		}
+	}
+}
+
+// writeOllamaModel lays out name's manifest and GGUF blob like Ollama does and
+// returns the manifest digest /api/tags would report.
+func writeOllamaModel(t *testing.T, dir, name string, gguf []byte) (digest, blob string) {
+	t.Helper()
+	layerDigest := "sha256:" + sha256Bytes(gguf)
+	blob = ollamaBlobPath(dir, layerDigest)
+	manifest := []byte(fmt.Sprintf(`{"schemaVersion":2,"layers":[{"mediaType":"%s","digest":"%s","size":%d}]}`,
+		ollamaModelMediaType, layerDigest, len(gguf)))
+	path := ollamaManifestPath(dir, name)
+	for file, data := range map[string][]byte{blob: gguf, path: manifest} {
+		if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sha256Bytes(manifest), blob
+}
+
+// minimalGGUF is a GGUF v3 header with only general.architecture and no tensors.
+func minimalGGUF(arch string) []byte {
+	var b bytes.Buffer
+	le := func(v any) { binary.Write(&b, binary.LittleEndian, v) }
+	b.WriteString("GGUF")
+	le(uint32(3))
+	le(uint64(0)) // tensors
+	le(uint64(1)) // metadata keys
+	key := "general.architecture"
+	le(uint64(len(key)))
+	b.WriteString(key)
+	le(uint32(8)) // string
+	le(uint64(len(arch)))
+	b.WriteString(arch)
+	return b.Bytes()
+}
+
+func TestVerifyOllamaModelHashesBlob(t *testing.T) {
+	calls := 0
+	var submitted verifyModelRequest
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		json.NewDecoder(r.Body).Decode(&submitted)
+		w.Write([]byte(`{"verification_status":"verified"}`))
+	}))
+	defer ts.Close()
+
+	const alias = "gguf/llama3:8b"
+	newVerifier := func(dir string) *Verifier {
+		v := NewVerifier(&Catalog{entries: map[string]CatalogEntry{alias: {ID: "1"}}}, NewServerClient(ts.URL, "k"), "ollama", "", "")
+		v.SetOllamaModels(dir)
+		return v
+	}
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	digest, blob := writeOllamaModel(t, dir, "llama3:8b", minimalGGUF("llama"))
+	res, err := newVerifier(dir).VerifyOllamaModel(ctx, alias, "sha256:"+digest, 12)
+	if err != nil || res.Status != StatusVerified || calls != 1 {
+		t.Fatalf("intact model: %+v, %v, %d calls", res, err, calls)
+	}
+	if submitted.GGUF == nil || submitted.GGUF.Architecture != "llama" || res.GGUF == nil {
+		t.Fatalf("GGUF header not submitted: %+v", submitted.GGUF)
+	}
+
+	// Each case tampers further with the same directory, so order matters.
+	cases := []struct {
+		name   string
+		tamper func()
+		digest string
+		reason string
+	}{
+		{"manifest mismatch", func() {}, sha256Bytes([]byte("other")), ReasonManifestMismatch},
+		{"blob modified", func() { os.WriteFile(blob, []byte("GGUF weightz"), 0644) }, digest, ReasonBlobMismatch},
+		{"blob deleted", func() { os.Remove(blob) }, digest, ReasonBlobMissing},
+		{"not on disk", func() { os.RemoveAll(filepath.Join(dir, "manifests")) }, digest, ReasonManifestMissing},
+	}
+	for _, c := range cases {
+		c.tamper()
+		res, err := newVerifier(dir).VerifyOllamaModel(ctx, alias, c.digest, 12)
+		var blobErr *BlobError
+		if !errors.As(err, &blobErr) || res.Status != StatusFailed || res.Reason != c.reason {
+			t.Errorf("%s: %+v, %v; want failed with %s", c.name, res, err, c.reason)
+		}
+	}
+	if calls != 1 {
+		t.Fatalf("platform called %d times; local failures must not be submitted", calls)
+	}
+
+	// Without access to the models dir (Ollama in another container), the
+	// digest alone is submitted.
+	submitted = verifyModelRequest{}
+	res, err = newVerifier(filepath.Join(t.TempDir(), "not-mounted")).VerifyOllamaModel(ctx, alias, "sha256:"+digest, 12)
+	if err != nil || res.Status != StatusVerified || calls != 2 || submitted.Digest != digest || submitted.GGUF != nil {
+		t.Fatalf("no models dir: %+v, %v, %d calls, submitted %+v", res, err, calls, submitted)
+	}
+}
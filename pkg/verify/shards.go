@@ -0,0 +1,95 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// shardIndexSuffix names a sharded safetensors checkpoint's index, e.g.
+// model.safetensors.index.json next to model-00001-of-00004.safetensors.
+const shardIndexSuffix = ".safetensors.index.json"
+
+// shardIndex is the part of a safetensors index that lists the shards.
+type shardIndex struct {
+	WeightMap map[string]string `json:"weight_map"` // tensor name -> shard file
+}
+
+// ShardError reports a sharded checkpoint whose files do not match its index.
+type ShardError struct {
+	Index   string   // index file, relative to the weight dir
+	Missing []string // shards the index lists that are not on disk
+	Extra   []string // shard-named files on disk the index does not list
+}
+
+func (e *ShardError) Error() string {
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Extra) > 0 {
+		parts = append(parts, "unexpected "+strings.Join(e.Extra, ", "))
+	}
+	return fmt.Sprintf("incomplete sharded checkpoint %s: %s", e.Index, strings.Join(parts, "; "))
+}
+
+// checkShardIndexes compares every safetensors index under root with the files
+// in stats, so a partial download fails locally instead of at the platform.
+// Shard names in an index are relative to the index's directory. A file counts
+// as extra when it is named like one of the index's shards (prefix-NNNNN-of-NNNNN)
+// but is not listed; other safetensors files, such as Mistral's
+// consolidated.safetensors, are left alone.
+func checkShardIndexes(root string, stats map[string]fileStat) error {
+	var indexes []string
+	for name := range stats {
+		if strings.HasSuffix(name, shardIndexSuffix) {
+			indexes = append(indexes, name)
+		}
+	}
+	sort.Strings(indexes)
+
+	for _, name := range indexes {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if err != nil {
+			return err
+		}
+		var idx shardIndex
+		if err := json.Unmarshal(data, &idx); err != nil {
+			return fmt.Errorf("parse %s: %w", name, err)
+		}
+		if len(idx.WeightMap) == 0 {
+			return fmt.Errorf("parse %s: empty weight_map", name)
+		}
+
+		dir := path.Dir(name)
+		prefix := strings.TrimSuffix(path.Base(name), shardIndexSuffix) + "-"
+		listed := make(map[string]bool)
+		for _, shard := range idx.WeightMap {
+			listed[path.Join(dir, shard)] = true
+		}
+
+		shardErr := &ShardError{Index: name}
+		for shard := range listed {
+			if _, ok := stats[shard]; !ok {
+				shardErr.Missing = append(shardErr.Missing, shard)
+			}
+		}
+		for file := range stats {
+			base := path.Base(file)
+			if path.Dir(file) == dir && !listed[file] && strings.HasPrefix(base, prefix) &&
+				strings.Contains(base, "-of-") && strings.HasSuffix(base, ".safetensors") {
+				shardErr.Extra = append(shardErr.Extra, file)
+			}
+		}
+		if len(shardErr.Missing) > 0 || len(shardErr.Extra) > 0 {
+			sort.Strings(shardErr.Missing)
+			sort.Strings(shardErr.Extra)
+			return shardErr
+		}
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package verify
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+const twoShardIndex = `{"metadata": {"total_size": 2}, "weight_map": {
+	"a.weight": "model-00001-of-00002.safetensors",
+	"b.weight": "model-00002-of-00002.safetensors"}}`
+
+func TestCheckShardIndexes(t *testing.T) {
+	cases := map[string]struct {
+		files   map[string]string
+		missing []string
+		extra   []string
+	}{
+		"complete": {files: map[string]string{
+			"model.safetensors.index.json":     twoShardIndex,
+			"model-00001-of-00002.safetensors": "1",
+			"model-00002-of-00002.safetensors": "2",
+			"consolidated.safetensors":         "not a shard of this index",
+		}},
+		"partial download": {
+			files: map[string]string{
+				"model.safetensors.index.json":     twoShardIndex,
+				"model-00001-of-00002.safetensors": "1",
+			},
+			missing: []string{"model-00002-of-00002.safetensors"},
+		},
+		"leftover from another revision": {
+			files: map[string]string{
+				"model.safetensors.index.json":     twoShardIndex,
+				"model-00001-of-00002.safetensors": "1",
+				"model-00002-of-00002.safetensors": "2",
+				"model-00001-of-00003.safetensors": "old",
+			},
+			extra: []string{"model-00001-of-00003.safetensors"},
+		},
+		"index in a subfolder": {
+			files: map[string]string{
+				"transformer/diffusion_pytorch_model.safetensors.index.json": `{"weight_map": {"w": "diffusion_pytorch_model-00001-of-00001.safetensors"}}`,
+			},
+			missing: []string{"transformer/diffusion_pytorch_model-00001-of-00001.safetensors"},
+		},
+	}
+	for name, c := range cases {
+		root := t.TempDir()
+		writeFiles(t, root, c.files)
+		stats, err := weightDirStats(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = checkShardIndexes(root, stats)
+		if c.missing == nil && c.extra == nil {
+			if err != nil {
+				t.Errorf("%s: %v", name, err)
+			}
+			continue
+		}
+		var shardErr *ShardError
+		if !errors.As(err, &shardErr) {
+			t.Errorf("%s: err = %v, want a ShardError", name, err)
+			continue
+		}
+		if !reflect.DeepEqual(shardErr.Missing, c.missing) || !reflect.DeepEqual(shardErr.Extra, c.extra) {
+			t.Errorf("%s: missing %v, extra %v; want %v, %v", name, shardErr.Missing, shardErr.Extra, c.missing, c.extra)
+		}
+	}
+}
+
+func TestWeightDirStatsWalksSubdirectories(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		"config.json":                 "{}",
+		"tokenizer/tokenizer.json":    "{}",
+		"text_encoder/model.bin":      "w",
+		".cache/huggingface/download": "lock",
+	})
+	stats, err := weightDirStats(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for name := range stats {
+		names = append(names, name)
+	}
+	files, _, err := measureWeightDir(root, stats, nil, 2, &progressTracker{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var measured []string
+	for _, f := range files {
+		measured = append(measured, f.Name)
+	}
+	want := []string{"config.json", "text_encoder/model.bin", "tokenizer/tokenizer.json"}
+	if !reflect.DeepEqual(measured, want) {
+		t.Fatalf("measured %v (stats %v), want %v", measured, names, want)
+	}
+}
+
+func TestWeightDirStatsFollowsSymlinkedRoot(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"config.json": "{}", "tokenizer/tokenizer.json": "{}"})
+	root := filepath.Join(t.TempDir(), "model")
+	if err := os.Symlink(dir, root); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+	stats, err := weightDirStats(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 2 {
+		t.Fatalf("stats = %v, want both files under the linked directory", stats)
+	}
+	if _, ok := stats["tokenizer/tokenizer.json"]; !ok {
+		t.Fatalf("stats = %v", stats)
+	}
+}
@@ -0,0 +1,171 @@
+package verify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// State is the verifier's caches as saved across restarts: per-file weight
+// hashes, so unchanged files are not re-read, and recent verification results.
+// Both are dropped when the catalog differs from the one they were saved under.
+type State struct {
+	Catalog string                 `json:"catalog"` // hash of the catalog fingerprint
+	Hashes  map[string]HashState   `json:"hashes"`  // by weight file path
+	Results map[string]ResultState `json:"results"` // by alias
+}
+
+// HashState is a weight file's measurement and the stat it was taken at.
+type HashState struct {
+	Stat StatState       `json:"stat"`
+	File FileMeasurement `json:"file"`
+}
+
+// StatState is a weight file's size, modification time and inode.
+type StatState struct {
+	Size    int64  `json:"size"`
+	ModTime int64  `json:"mod_time"` // unix nanoseconds
+	Inode   uint64 `json:"inode,omitempty"`
+}
+
+// ResultState is a cached verification result.
+type ResultState struct {
+	Alias             string               `json:"alias"`
+	Status            Status               `json:"status"`
+	Digest            string               `json:"digest"`
+	WeightFingerprint string               `json:"weight_fingerprint"`
+	SizeBytes         int64                `json:"size_bytes"`
+	CachedAt          time.Time            `json:"cached_at"`
+	CachedDigest      string               `json:"cached_digest,omitempty"`
+	CachedSize        int64                `json:"cached_size,omitempty"`
+	Stats             map[string]StatState `json:"stats,omitempty"`
+	GGUF              *gguf.Metadata       `json:"gguf,omitempty"`
+}
+
+// State returns the verifier's caches for saving.
+func (v *Verifier) State() State {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	st := State{
+		Catalog: v.catalogHash(),
+		Hashes:  make(map[string]HashState, len(v.hashes)),
+		Results: make(map[string]ResultState, len(v.resultCache)),
+	}
+	if v.restoredPending {
+		st.Catalog = v.restoredCatalog // still unconfirmed; keep the original tag
+	}
+	for path, h := range v.hashes {
+		st.Hashes[path] = HashState{Stat: saveStat(h.stat), File: h.file}
+	}
+	for alias, entry := range v.resultCache {
+		if !verifyResultFresh(entry) {
+			continue
+		}
+		st.Results[alias] = ResultState{
+			Alias:             entry.result.Alias,
+			Status:            entry.result.Status,
+			Digest:            entry.result.Digest,
+			WeightFingerprint: entry.result.WeightFingerprint,
+			SizeBytes:         entry.result.SizeBytes,
+			GGUF:              entry.result.GGUF,
+			CachedAt:          entry.cachedAt,
+			CachedDigest:      entry.digest,
+			CachedSize:        entry.size,
+			Stats:             saveStats(entry.stats),
+		}
+	}
+	return st
+}
+
+// RestoreState loads saved caches. They are checked against the catalog now,
+// or, if none is loaded yet, after the next successful RefreshCatalog.
+func (v *Verifier) RestoreState(st State) {
+	v.mu.Lock()
+	for path, h := range st.Hashes {
+		v.hashes[path] = cachedHash{stat: restoreStat(h.Stat), file: h.File}
+	}
+	for alias, r := range st.Results {
+		v.resultCache[alias] = &verifyResultEntry{
+			result: Result{
+				Alias:             r.Alias,
+				Status:            r.Status,
+				Digest:            r.Digest,
+				WeightFingerprint: r.WeightFingerprint,
+				SizeBytes:         r.SizeBytes,
+				GGUF:              r.GGUF,
+			},
+			cachedAt: r.CachedAt,
+			digest:   r.CachedDigest,
+			size:     r.CachedSize,
+			stats:    restoreStats(r.Stats),
+		}
+	}
+	v.restoredCatalog, v.restoredPending = st.Catalog, true
+	v.mu.Unlock()
+
+	v.checkRestoredCatalog()
+}
+
+// checkRestoredCatalog drops restored caches if the loaded catalog is not the
+// one they were saved under. Without a catalog yet, the check waits.
+func (v *Verifier) checkRestoredCatalog() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if !v.restoredPending || v.catalog == nil || v.catalog.fingerprint() == "" {
+		return
+	}
+	v.restoredPending = false
+	if v.catalogHash() == v.restoredCatalog {
+		return
+	}
+	logger.Info("Approved catalog changed since the last run; re-measuring weights",
+		zap.Int("dropped_hashes", len(v.hashes)),
+		zap.Int("dropped_results", len(v.resultCache)))
+	v.hashes = make(map[string]cachedHash)
+	v.cache = make(map[string]*fingerprintCache)
+	v.resultCache = make(map[string]*verifyResultEntry)
+}
+
+func (v *Verifier) catalogHash() string {
+	if v.catalog == nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(v.catalog.fingerprint()))
+	return hex.EncodeToString(sum[:])
+}
+
+func saveStat(s fileStat) StatState {
+	return StatState{Size: s.size, ModTime: s.modTime, Inode: s.inode}
+}
+
+func restoreStat(s StatState) fileStat {
+	return fileStat{size: s.Size, modTime: s.ModTime, inode: s.Inode}
+}
+
+func saveStats(stats map[string]fileStat) map[string]StatState {
+	if stats == nil {
+		return nil
+	}
+	out := make(map[string]StatState, len(stats))
+	for name, s := range stats {
+		out[name] = saveStat(s)
+	}
+	return out
+}
+
+func restoreStats(stats map[string]StatState) map[string]fileStat {
+	if stats == nil {
+		return nil
+	}
+	out := make(map[string]fileStat, len(stats))
+	for name, s := range stats {
+		out[name] = restoreStat(s)
+	}
+	return out
+}
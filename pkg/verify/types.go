@@ -1,5 +1,7 @@
 package verify
 
+import "github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
+
 // Status is the model integrity verification outcome reported to the platform.
 type Status string
 
@@ -13,21 +15,22 @@ const (
 
 // CatalogEntry is a public approved-model row (no verification secrets).
 type CatalogEntry struct {
-	ID               string   `json:"id"`
-	Alias            string   `json:"alias"`
-	ServiceType      string   `json:"service_type"`
-	DisplayName      string   `json:"display_name"`
-	Description      *string  `json:"description,omitempty"`
-	CardImage        string   `json:"card_image"`
-	HFRepo           *string  `json:"hf_repo,omitempty"`
-	HFRef            *string  `json:"hf_ref,omitempty"`
-	MinSizeBytes     int64    `json:"min_size_bytes"`
-	IsActive         bool     `json:"is_active"`
-	InputPricePer1M  *float64 `json:"input_price_per_1m,omitempty"`
-	OutputPricePer1M *float64 `json:"output_price_per_1m,omitempty"`
-	TransactionCount      int64   `json:"transaction_count"`
-	TotalProviderEarnings float64 `json:"total_provider_earnings"`
-	SortOrder             *int32  `json:"sort_order,omitempty"`
+	ID                    string   `json:"id"`
+	Alias                 string   `json:"alias"`
+	ServiceType           string   `json:"service_type"`
+	DisplayName           string   `json:"display_name"`
+	Description           *string  `json:"description,omitempty"`
+	CardImage             string   `json:"card_image"`
+	HFRepo                *string  `json:"hf_repo,omitempty"`
+	HFRef                 *string  `json:"hf_ref,omitempty"`
+	MinSizeBytes          int64    `json:"min_size_bytes"`
+	IsActive              bool     `json:"is_active"`
+	InputPricePer1M       *float64 `json:"input_price_per_1m,omitempty"`
+	OutputPricePer1M      *float64 `json:"output_price_per_1m,omitempty"`
+	TransactionCount      int64    `json:"transaction_count"`
+	TotalProviderEarnings float64  `json:"total_provider_earnings"`
+	SortOrder             *int32   `json:"sort_order,omitempty"`
+	Canaries              []Canary `json:"canaries,omitempty"` // runtime attestation prompts
 }
 
 // catalogResponse is the public list from GET /api/models/approved-builds.
@@ -54,12 +57,14 @@ type ManifestEntry struct {
 
 // verifyModelRequest is POST /api/provider/verify-model.
 type verifyModelRequest struct {
-	Alias       string            `json:"alias"`
-	ServiceType string            `json:"service_type,omitempty"`
-	Digest      string            `json:"digest,omitempty"`
-	SizeBytes   int64             `json:"size_bytes,omitempty"`
-	Files       []FileMeasurement `json:"files,omitempty"`
-	Stale       bool              `json:"stale,omitempty"`
+	Alias        string            `json:"alias"`
+	ServiceType  string            `json:"service_type,omitempty"`
+	Digest       string            `json:"digest,omitempty"`
+	SizeBytes    int64             `json:"size_bytes,omitempty"`
+	Files        []FileMeasurement `json:"files,omitempty"`
+	Stale        bool              `json:"stale,omitempty"`
+	GGUF         *gguf.Metadata    `json:"gguf,omitempty"`
+	Attestations []Attestation     `json:"attestations,omitempty"`
 }
 
 // verifyModelResponse is the server-as-judge verification result.
@@ -67,6 +72,7 @@ type verifyModelResponse struct {
 	VerificationStatus string `json:"verification_status"`
 	Digest             string `json:"digest,omitempty"`
 	WeightFingerprint  string `json:"weight_fingerprint,omitempty"`
+	Attestation        string `json:"attestation,omitempty"` // "mismatch" when canary outputs differ from the served model's
 }
 
 // Result holds verification output for one model alias.
@@ -76,4 +82,7 @@ type Result struct {
 	Digest            string
 	WeightFingerprint string
 	SizeBytes         int64
+	Reason            string         // why a local check failed (Reason constants); empty otherwise
+	GGUF              *gguf.Metadata // Ollama GGUF header facts, when read
+	Attestations      []Attestation  // canary output hashes submitted with the measurements
 }
@@ -2,21 +2,29 @@ package verify
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/gguf"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
 	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
 	"go.uber.org/zap"
 )
 
+// fileStat identifies a version of a weight file. The inode catches a file
+// replaced by another of the same size and mtime; it is 0 where unsupported.
 type fileStat struct {
 	size    int64
 	modTime int64
+	inode   uint64
 }
 
 type fingerprintCache struct {
@@ -24,6 +32,12 @@ type fingerprintCache struct {
 	stats map[string]fileStat
 }
 
+// cachedHash is one weight file's measurement and the stat it was taken at.
+type cachedHash struct {
+	stat fileStat
+	file FileMeasurement
+}
+
 type verifyResultEntry struct {
 	result   Result
 	cachedAt time.Time
@@ -41,10 +55,44 @@ type Verifier struct {
 	serviceType       string
 	hfHubCache        string
 	modelPathOverride string
+	ollamaModels      string // Ollama models dir; empty = DefaultOllamaModels
 
 	mu          sync.Mutex
 	cache       map[string]*fingerprintCache // alias -> weight fingerprint cache (vLLM)
+	hashes      map[string]cachedHash        // weight file path -> measurement, persisted across restarts
 	resultCache map[string]*verifyResultEntry
+	measuring   map[string]chan struct{} // alias -> closed when its measurement finishes
+	hashWorkers int                      // 0 = DefaultHashWorkers
+	// ollamaDirMissing is set once the Ollama models dir was found unreadable and logged.
+	ollamaDirMissing bool
+
+	progressMu sync.Mutex
+	progress   map[string]*progressTracker // alias -> measurement in flight
+
+	// Filesystem watching (StartWatching): aliases whose files changed are
+	// blocked until re-verified; generation counts changes per alias.
+	watcher        *watcher
+	settle         time.Duration
+	generation     map[string]uint64
+	reverifying    map[string]bool
+	reverifyTimers map[string]*time.Timer
+
+	// Inference allowlist (worker.go): CheckInference reads the snapshot;
+	// misses and stale entries wait in queue for the background worker.
+	allowed atomic.Pointer[allowlist]
+	allowMu sync.Mutex // serializes allowlist writers
+	queue   chan string
+	queued  sync.Map // alias -> struct{} while in queue
+
+	// Runtime attestation (EnableAttestation): canary outputs per alias.
+	attestClient   llm.Client
+	localCanaries  []Canary
+	attestInterval time.Duration
+	attestCache    map[string]*attestEntry
+
+	// Catalog hash restored caches were saved under, checked once a catalog is loaded.
+	restoredCatalog string
+	restoredPending bool
 }
 
 // NewVerifier creates a verifier. Measurements are sent to the server; expected hashes stay in the DB.
@@ -56,8 +104,48 @@ func NewVerifier(catalog *Catalog, server *ServerClient, serviceType, hfHubCache
 		hfHubCache:        strings.TrimSpace(hfHubCache),
 		modelPathOverride: strings.TrimSpace(modelPathOverride),
 		cache:             make(map[string]*fingerprintCache),
+		hashes:            make(map[string]cachedHash),
 		resultCache:       make(map[string]*verifyResultEntry),
+		measuring:         make(map[string]chan struct{}),
+		progress:          make(map[string]*progressTracker),
+		settle:            watchSettle,
+		generation:        make(map[string]uint64),
+		reverifying:       make(map[string]bool),
+		reverifyTimers:    make(map[string]*time.Timer),
+		queue:             make(chan string, workerQueueSize),
+		attestCache:       make(map[string]*attestEntry),
+	}
+}
+
+// SetOllamaModels sets the Ollama models directory holding manifests and blobs.
+// Empty uses DefaultOllamaModels.
+func (v *Verifier) SetOllamaModels(dir string) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.ollamaModels = strings.TrimSpace(dir)
+}
+
+// SetHashWorkers caps how many weight files are hashed in parallel. 0 uses
+// DefaultHashWorkers; the pool still ramps up only while throughput improves.
+func (v *Verifier) SetHashWorkers(n int) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.hashWorkers = n
+}
+
+// Progress returns the weight measurements in flight, ordered by alias.
+func (v *Verifier) Progress() []Progress {
+	if v == nil {
+		return nil
 	}
+	v.progressMu.Lock()
+	defer v.progressMu.Unlock()
+	out := make([]Progress, 0, len(v.progress))
+	for _, t := range v.progress {
+		out = append(out, t.snapshot())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Alias < out[j].Alias })
+	return out
 }
 
 func (v *Verifier) resolveVLLMRoot(entry CatalogEntry, alias string) (string, error) {
@@ -84,24 +172,61 @@ func (v *Verifier) resolveVLLMRoot(entry CatalogEntry, alias string) (string, er
 	return ResolveHFModelRoot(hub, repo, ref)
 }
 
-// VerifyOllamaModel submits digest and size to the server for verification.
+// VerifyOllamaModel checks the model's manifest and GGUF blob on disk against
+// the digest Ollama reports, then submits digest and size to the server. When
+// Ollama's models dir cannot be read (Ollama in another container without a
+// mounted volume), only the digest and size are submitted.
 func (v *Verifier) VerifyOllamaModel(ctx context.Context, alias, digest string, sizeBytes int64) (Result, error) {
 	res := Result{Alias: alias, Digest: NormalizeDigest(digest), SizeBytes: sizeBytes}
 
-	if _, ok := v.catalog.Get(alias); !ok {
-		res.Status = StatusUnverified
+	entry, ok := v.catalog.Get(alias)
+	if !ok {
+		res.Status = v.notInCatalog()
 		return res, nil
 	}
 
+	dir := v.readableOllamaModels()
+	if dir != "" {
+		v.watchOllama(alias, dir)
+	}
+
 	if cached, ok := v.cachedOllamaResult(alias, res.Digest, sizeBytes); ok {
 		return cached, nil
 	}
 
+	// /api/tags only reports the manifest digest; a tampered blob behind an
+	// untouched manifest is caught by rehashing it here.
+	if dir != "" {
+		blob, err := v.checkOllamaBlob(alias, dir, res.Digest)
+		if err != nil {
+			res.Status = StatusFailed
+			var blobErr *BlobError
+			if errors.As(err, &blobErr) {
+				res.Reason = blobErr.Reason
+			}
+			return res, err
+		}
+		if meta, err := gguf.ReadFile(blob); err != nil {
+			logger.Warn("Could not read GGUF header", zap.String("alias", alias), zap.Error(err))
+		} else {
+			res.GGUF = &meta
+		}
+	}
+
+	// The blob on disk is what Ollama should be serving; canaries check that it is.
+	var err error
+	if res.Attestations, err = v.attest(ctx, alias, res.Digest, entry); err != nil {
+		attestFailure(&res, err)
+		return res, err
+	}
+
 	resp, err := v.server.VerifyModel(ctx, verifyModelRequest{
-		Alias:       alias,
-		ServiceType: v.serviceType,
-		Digest:      res.Digest,
-		SizeBytes:   sizeBytes,
+		Alias:        alias,
+		ServiceType:  v.serviceType,
+		Digest:       res.Digest,
+		SizeBytes:    sizeBytes,
+		GGUF:         res.GGUF,
+		Attestations: res.Attestations,
 	})
 	if err != nil {
 		res.Status = StatusFailed
@@ -118,7 +243,7 @@ func (v *Verifier) VerifyVLLMModel(ctx context.Context, alias string) (Result, e
 
 	entry, ok := v.catalog.Get(alias)
 	if !ok {
-		res.Status = StatusUnverified
+		res.Status = v.notInCatalog()
 		return res, nil
 	}
 
@@ -134,21 +259,40 @@ func (v *Verifier) VerifyVLLMModel(ctx context.Context, alias string) (Result, e
 		return res, err
 	}
 
+	v.watchVLLM(alias, root, currentStats)
+
 	if cached, ok := v.cachedVLLMResult(alias, currentStats); ok {
 		return cached, nil
 	}
 
+	// A partial download of a sharded checkpoint fails here, before hashing.
+	if err := checkShardIndexes(root, currentStats); err != nil {
+		res.Status = StatusFailed
+		var shardErr *ShardError
+		if errors.As(err, &shardErr) {
+			res.Reason = ReasonIncompleteShards
+		}
+		return res, err
+	}
+
 	files, stale, err := v.measureWithCache(alias, root)
 	if err != nil {
 		res.Status = StatusFailed
 		return res, err
 	}
 
+	// Hashes prove what is on disk; canaries check that vLLM serves it under this name.
+	if res.Attestations, err = v.attest(ctx, alias, measurementKey(files), entry); err != nil {
+		attestFailure(&res, err)
+		return res, err
+	}
+
 	resp, err := v.server.VerifyModel(ctx, verifyModelRequest{
-		Alias:       alias,
-		ServiceType: v.serviceType,
-		Files:       files,
-		Stale:       stale,
+		Alias:        alias,
+		ServiceType:  v.serviceType,
+		Files:        files,
+		Stale:        stale,
+		Attestations: res.Attestations,
 	})
 	if err != nil {
 		res.Status = StatusFailed
@@ -230,42 +374,229 @@ func (v *Verifier) measureWithCache(alias, root string) (files []FileMeasurement
 		return nil, false, err
 	}
 
+	// Hashing runs without v.mu so other models are served meanwhile; a second
+	// caller for the same alias waits for the first and then uses its cache.
 	v.mu.Lock()
-	defer v.mu.Unlock()
-
-	prev, hadCache := v.cache[alias]
-	if hadCache && fileStatsEqual(prev.stats, currentStats) {
-		return prev.files, false, nil
+	for {
+		prev, hadCache := v.cache[alias]
+		if hadCache && fileStatsEqual(prev.stats, currentStats) {
+			v.mu.Unlock()
+			return prev.files, false, nil
+		}
+		done, busy := v.measuring[alias]
+		if !busy {
+			break
+		}
+		v.mu.Unlock()
+		<-done
+		v.mu.Lock()
+	}
+	done := make(chan struct{})
+	v.measuring[alias] = done
+	known := make(map[string]cachedHash)
+	for path, h := range v.hashes {
+		if _, ok := weightName(root, path); ok {
+			known[path] = h
+		}
 	}
+	workers := v.hashWorkers
+	v.mu.Unlock()
+
+	tracker := v.trackProgress(alias)
+	files, hashed, err := measureWeightDir(root, currentStats, known, workers, tracker)
+	v.untrackProgress(alias)
 
-	files, err = measureWeightDir(root)
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.measuring, alias)
+	close(done)
 	if err != nil {
 		return nil, false, err
 	}
+	for path, h := range hashed {
+		v.hashes[path] = h
+	}
+	for path := range v.hashes {
+		if name, ok := weightName(root, path); ok {
+			if _, ok := currentStats[name]; !ok {
+				delete(v.hashes, path) // file removed from the weight dir
+			}
+		}
+	}
 
+	_, hadCache := v.cache[alias]
 	v.cache[alias] = &fingerprintCache{files: files, stats: currentStats}
 	return files, hadCache, nil
 }
 
+func (v *Verifier) trackProgress(alias string) *progressTracker {
+	tracker := &progressTracker{p: Progress{Alias: alias, StartedAt: time.Now()}}
+	v.progressMu.Lock()
+	v.progress[alias] = tracker
+	v.progressMu.Unlock()
+	return tracker
+}
+
+func (v *Verifier) untrackProgress(alias string) {
+	v.progressMu.Lock()
+	delete(v.progress, alias)
+	v.progressMu.Unlock()
+}
+
+// ollamaModelsDir is the configured Ollama models dir, else the default.
+func (v *Verifier) ollamaModelsDir() (string, error) {
+	v.mu.Lock()
+	dir := v.ollamaModels
+	v.mu.Unlock()
+	if dir == "" {
+		return DefaultOllamaModels()
+	}
+	return dir, nil
+}
+
+// readableOllamaModels returns the Ollama models dir, or "" if it is missing
+// or cannot be read. The first miss is logged; blob checks resume once the
+// directory is readable again.
+func (v *Verifier) readableOllamaModels() string {
+	dir, err := v.ollamaModelsDir()
+	if err == nil {
+		var f *os.File
+		if f, err = os.Open(dir); err == nil {
+			f.Close()
+		}
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if err != nil {
+		if !v.ollamaDirMissing {
+			logger.Warn("Ollama models directory not readable; verifying Ollama models by digest only (mount it and set provider.ollama_models to check blobs)",
+				zap.String("dir", dir), zap.Error(err))
+			v.ollamaDirMissing = true
+		}
+		return ""
+	}
+	v.ollamaDirMissing = false
+	return dir
+}
+
+// checkOllamaBlob confirms that alias's manifest hashes to digest and that its
+// GGUF blob hashes to the layer digest in the manifest, and returns the blob's
+// path. The blob hash is kept in the per-file cache, so an unchanged blob is
+// read once.
+func (v *Verifier) checkOllamaBlob(alias, dir, digest string) (string, error) {
+	name := ollamaModelName(alias)
+	layer, err := readOllamaManifest(dir, name, digest)
+	if err != nil {
+		return "", err
+	}
+	path := ollamaBlobPath(dir, layer.Digest)
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return "", &BlobError{Model: name, Reason: ReasonBlobMissing, Path: path}
+	}
+	if err != nil {
+		return "", err
+	}
+	stat := fileStat{size: info.Size(), modTime: info.ModTime().UnixNano(), inode: fileInode(info)}
+
+	hash, err := v.blobHash(alias, path, stat)
+	if err != nil {
+		return "", err
+	}
+	if want := NormalizeDigest(layer.Digest); hash != want {
+		return "", &BlobError{Model: name, Reason: ReasonBlobMismatch, Path: path, Want: want, Got: hash}
+	}
+	return path, nil
+}
+
+// blobHash is the full SHA-256 of a blob, from the per-file cache when its stat
+// is unchanged. Like measureWithCache it hashes outside v.mu, one alias at a time.
+func (v *Verifier) blobHash(alias, path string, stat fileStat) (string, error) {
+	v.mu.Lock()
+	for {
+		if h, ok := v.hashes[path]; ok && h.stat == stat {
+			v.mu.Unlock()
+			return h.file.Hash, nil
+		}
+		done, busy := v.measuring[alias]
+		if !busy {
+			break
+		}
+		v.mu.Unlock()
+		<-done
+		v.mu.Lock()
+	}
+	done := make(chan struct{})
+	v.measuring[alias] = done
+	v.mu.Unlock()
+
+	tracker := v.trackProgress(alias)
+	tracker.mu.Lock()
+	tracker.p.FilesTotal, tracker.p.BytesTotal = 1, stat.size
+	tracker.mu.Unlock()
+	files, err := hashFiles([]hashJob{{name: filepath.Base(path), path: path, method: "full", stat: stat}}, 1, tracker)
+	v.untrackProgress(alias)
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	delete(v.measuring, alias)
+	close(done)
+	if err != nil {
+		return "", err
+	}
+	v.hashes[path] = cachedHash{stat: stat, file: files[0]}
+	return files[0].Hash, nil
+}
+
+// weightDirStats stats every file under root, keyed by slash-separated path
+// relative to root (e.g. "tokenizer/vocab.json"). Hidden directories such as
+// .git and the .cache left by `huggingface-cli download --local-dir` are skipped.
+// A symlinked root (e.g. model_path pointing into another disk) is resolved
+// first, since WalkDir does not follow it.
 func weightDirStats(root string) (map[string]fileStat, error) {
-	entries, err := os.ReadDir(root)
+	root, err := filepath.EvalSymlinks(root)
 	if err != nil {
 		return nil, err
 	}
 	stats := make(map[string]fileStat)
-	for _, entry := range entries {
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
 		if entry.IsDir() {
-			continue
+			if path != root && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
 		}
-		info, err := entry.Info()
+		// Follow symlinks: Hugging Face snapshots link each file to a blob.
+		info, err := os.Stat(path)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		stats[entry.Name()] = fileStat{size: info.Size(), modTime: info.ModTime().UnixNano()}
+		if info.IsDir() {
+			return nil // linked directories are not followed
+		}
+		name, _ := weightName(root, path)
+		stats[name] = fileStat{size: info.Size(), modTime: info.ModTime().UnixNano(), inode: fileInode(info)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return stats, nil
 }
 
+// weightName is path's measurement name under root, or false if it is not under root.
+func weightName(root, path string) (string, bool) {
+	rel, err := filepath.Rel(root, path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
 func fileStatsEqual(a, b map[string]fileStat) bool {
 	if len(a) != len(b) {
 		return false
@@ -300,24 +631,68 @@ func applyServerResponse(res *Result, resp verifyModelResponse) {
 	if resp.WeightFingerprint != "" {
 		res.WeightFingerprint = strings.ToLower(strings.TrimSpace(resp.WeightFingerprint))
 	}
+	if resp.Attestation == "mismatch" && res.Status == StatusVerified {
+		res.Status = StatusFailed
+		res.Reason = ReasonAttestationMismatch
+	}
 }
 
-// ApplyToModels enriches discovered models with verification fields.
+// notInCatalog is the status of a model missing from the catalog: unverified
+// once the catalog is known, pending while it could not be fetched.
+func (v *Verifier) notInCatalog() Status {
+	if v.catalog.Loaded() {
+		return StatusUnverified
+	}
+	return StatusPending
+}
+
+// errorStatus is the status reported when verification returned an error. A
+// local check that judged the files (res.Reason set) fails the model; any
+// other error leaves it pending until the next attempt.
+func errorStatus(res Result) Status {
+	if res.Reason != "" {
+		return StatusFailed
+	}
+	return StatusPending
+}
+
+// ApplyToModels enriches discovered models with verification fields and
+// records the results in the inference allowlist.
 func (v *Verifier) ApplyToModels(ctx context.Context, llmClient llm.Client, models []llm.Model) []llm.Model {
+	out, inconclusive := v.verifyModels(ctx, llmClient, models)
+	v.publish(out, inconclusive, false)
+	return out
+}
+
+// verifyModels verifies models and returns them with verification fields set,
+// along with the aliases whose pending status comes from an error (Ollama tags
+// not listed, verify server or hashing failures) rather than a result.
+func (v *Verifier) verifyModels(ctx context.Context, llmClient llm.Client, models []llm.Model) ([]llm.Model, map[string]bool) {
 	var ollamaDetails map[string]ollamaDetail
+	tagsListed := true
 	if v.serviceType == "ollama" {
 		if oc, ok := llmClient.(*llm.OllamaClient); ok {
-			if tags, err := oc.ListTags(ctx); err == nil {
+			tags, err := oc.ListTags(ctx)
+			if err != nil {
+				logger.Warn("Failed to list Ollama tags for verification", zap.Error(err))
+				tagsListed = false
+			} else {
 				ollamaDetails = OllamaDetailsFromTags(tags)
 			}
 		}
 	}
 
 	out := make([]llm.Model, len(models))
+	inconclusive := make(map[string]bool)
 	for i, m := range models {
 		out[i] = m
 		switch v.serviceType {
 		case "ollama":
+			if !tagsListed {
+				out[i].VerificationStatus = string(StatusPending)
+				inconclusive[m.ID] = true
+				continue
+			}
 			detail, ok := ollamaDetails[m.ID]
 			if !ok {
 				out[i].VerificationStatus = string(StatusUnverified)
@@ -326,7 +701,9 @@ func (v *Verifier) ApplyToModels(ctx context.Context, llmClient llm.Client, mode
 			res, err := v.VerifyOllamaModel(ctx, m.ID, detail.Digest, detail.Size)
 			if err != nil {
 				logger.Error("Ollama verification error", zap.String("alias", m.ID), zap.Error(err))
-				out[i].VerificationStatus = string(StatusFailed)
+				out[i].VerificationStatus = string(errorStatus(res))
+				out[i].VerificationReason = res.Reason
+				inconclusive[m.ID] = res.Reason == ""
 				continue
 			}
 			applyResult(&out[i], res)
@@ -334,7 +711,9 @@ func (v *Verifier) ApplyToModels(ctx context.Context, llmClient llm.Client, mode
 			res, err := v.VerifyVLLMModel(ctx, m.ID)
 			if err != nil {
 				logger.Error("vLLM verification error", zap.String("alias", m.ID), zap.Error(err))
-				out[i].VerificationStatus = string(StatusFailed)
+				out[i].VerificationStatus = string(errorStatus(res))
+				out[i].VerificationReason = res.Reason
+				inconclusive[m.ID] = res.Reason == ""
 				continue
 			}
 			applyResult(&out[i], res)
@@ -342,7 +721,7 @@ func (v *Verifier) ApplyToModels(ctx context.Context, llmClient llm.Client, mode
 			out[i].VerificationStatus = string(StatusUnverified)
 		}
 	}
-	return out
+	return out, inconclusive
 }
 
 func applyResult(m *llm.Model, res Result) {
@@ -350,6 +729,8 @@ func applyResult(m *llm.Model, res Result) {
 	m.Digest = res.Digest
 	m.WeightFingerprint = res.WeightFingerprint
 	m.SizeBytes = res.SizeBytes
+	m.VerificationReason = res.Reason
+	m.GGUF = res.GGUF
 }
 
 // IsInferenceAllowed returns true when the model may serve traffic.
@@ -357,6 +738,16 @@ func IsInferenceAllowed(status string) bool {
 	return status == string(StatusVerified)
 }
 
+// IsSettled reports whether status is an outcome: verified, or judged failed
+// or unverified. Pending and stale models have not been decided yet.
+func IsSettled(status string) bool {
+	switch Status(status) {
+	case StatusVerified, StatusFailed, StatusUnverified:
+		return true
+	}
+	return false
+}
+
 type ollamaDetail struct {
 	Digest string
 	Size   int64
@@ -393,6 +784,7 @@ func (v *Verifier) RefreshCatalog(ctx context.Context) error {
 	if v.catalog.fingerprint() != before {
 		v.clearVerifyResultCache()
 	}
+	v.checkRestoredCatalog()
 	return nil
 }
 
@@ -405,18 +797,3 @@ func VerifiedModelIDs(models []llm.Model) []string {
 	}
 	return ids
 }
-
-func (v *Verifier) CheckInference(ctx context.Context, llmClient llm.Client, modelName string) error {
-	models := v.ApplyToModels(ctx, llmClient, []llm.Model{{ID: modelName, Object: "model", OwnedBy: v.serviceType}})
-	if len(models) == 0 {
-		return fmt.Errorf("model %s not found", modelName)
-	}
-	status := models[0].VerificationStatus
-	if status == "" {
-		status = string(StatusUnverified)
-	}
-	if !IsInferenceAllowed(status) {
-		return fmt.Errorf("model %s is not verified (%s)", modelName, status)
-	}
-	return nil
-}
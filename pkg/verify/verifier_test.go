@@ -1,10 +1,22 @@
 package verify
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
 )
 
+func TestMain(m *testing.M) {
+	logger.SetDefaultLogger(&logger.Logger{Logger: zap.NewNop()})
+	os.Exit(m.Run())
+}
+
 func TestApplyServerResponseKnownStatus(t *testing.T) {
 	res := Result{Alias: "test"}
 	applyServerResponse(&res, verifyModelResponse{VerificationStatus: "verified"})
@@ -29,6 +41,25 @@ func TestApplyServerResponseEmptyStatus(t *testing.T) {
 	}
 }
 
+func TestVerificationErrorsArePending(t *testing.T) {
+	// Before the catalog has loaded, a model missing from it may still be approved.
+	v := NewVerifier(NewCatalog("http://127.0.0.1:1", "vllm"), nil, "vllm", "", "")
+	if res, err := v.VerifyVLLMModel(context.Background(), "org/model"); err != nil || res.Status != StatusPending {
+		t.Fatalf("unloaded catalog: %+v, %v", res, err)
+	}
+
+	// Weights that cannot be found are an error, not a verdict on the model.
+	v = NewVerifier(&Catalog{entries: map[string]CatalogEntry{"org/model": {ID: "1"}}}, nil, "vllm", "", filepath.Join(t.TempDir(), "missing"))
+	v.hfHubCache = t.TempDir()
+	models := v.ApplyToModels(context.Background(), nil, []llm.Model{{ID: "org/model"}, {ID: "org/other"}})
+	if models[0].VerificationStatus != string(StatusPending) || models[1].VerificationStatus != string(StatusUnverified) {
+		t.Fatalf("statuses = %q, %q", models[0].VerificationStatus, models[1].VerificationStatus)
+	}
+	if !IsSettled(models[1].VerificationStatus) || IsSettled(models[0].VerificationStatus) {
+		t.Fatal("only the unapproved model has an outcome")
+	}
+}
+
 func TestVerifyResultCacheOllamaHitAndInvalidate(t *testing.T) {
 	v := &Verifier{resultCache: make(map[string]*verifyResultEntry)}
 	want := Result{Alias: "gguf/foo", Status: StatusVerified, Digest: "abc", SizeBytes: 42}
@@ -74,3 +105,80 @@ func TestVerifyResultCacheVLLMWeightChange(t *testing.T) {
 		t.Fatal("expected cache miss after weight stats change")
 	}
 }
+
+func TestVerifierStateRoundTrip(t *testing.T) {
+	catalog := &Catalog{entries: map[string]CatalogEntry{"org/model": {ID: "1"}}}
+	v := NewVerifier(catalog, nil, "vllm", "", "")
+	stats := map[string]fileStat{"model.safetensors": {size: 100, modTime: 1, inode: 7}}
+	v.hashes["/w/model.safetensors"] = cachedHash{stat: stats["model.safetensors"], file: FileMeasurement{Name: "model.safetensors", Hash: "h"}}
+	v.storeVLLMResult("org/model", stats, Result{Alias: "org/model", Status: StatusVerified})
+
+	restored := NewVerifier(catalog, nil, "vllm", "", "")
+	restored.RestoreState(v.State())
+	if got, ok := restored.cachedVLLMResult("org/model", stats); !ok || got.Status != StatusVerified {
+		t.Fatalf("restored result = %+v, %v", got, ok)
+	}
+	if h := restored.hashes["/w/model.safetensors"]; h.stat != stats["model.safetensors"] || h.file.Hash != "h" {
+		t.Fatalf("restored hash = %+v", h)
+	}
+
+	// Caches saved under another catalog are dropped.
+	changed := &Catalog{entries: map[string]CatalogEntry{"org/model": {ID: "2"}}}
+	other := NewVerifier(changed, nil, "vllm", "", "")
+	other.RestoreState(v.State())
+	if _, ok := other.cachedVLLMResult("org/model", stats); ok || len(other.hashes) != 0 {
+		t.Fatal("restored caches from a different catalog")
+	}
+
+	// Without a catalog yet, the check waits for one to load.
+	pending := NewVerifier(&Catalog{entries: map[string]CatalogEntry{}}, nil, "vllm", "", "")
+	pending.RestoreState(v.State())
+	if len(pending.hashes) != 1 || pending.State().Catalog != v.State().Catalog {
+		t.Fatal("restored caches were dropped before the catalog loaded")
+	}
+	pending.catalog.entries = changed.entries
+	pending.checkRestoredCatalog()
+	if len(pending.hashes) != 0 {
+		t.Fatal("restored caches kept after a different catalog loaded")
+	}
+}
+
+func TestMeasureWeightDirReusesHashes(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "pytorch_model.bin")
+	if err := os.WriteFile(path, []byte("weights-v1"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	known := make(map[string]cachedHash)
+	stats, err := weightDirStats(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, _, err := measureWeightDir(root, stats, known, 2, &progressTracker{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Same size, mtime and inode: the cached hash is used without reading the file.
+	mtime := time.Unix(1700000000, 0)
+	os.Chtimes(path, mtime, mtime)
+	stats, _ = weightDirStats(root)
+	known[path] = cachedHash{stat: stats["pytorch_model.bin"], file: FileMeasurement{Name: "pytorch_model.bin", Hash: "from-cache"}}
+	got, _, err := measureWeightDir(root, stats, known, 2, &progressTracker{})
+	if err != nil || got[0].Hash != "from-cache" {
+		t.Fatalf("measure = %+v, %v; want the cached hash", got, err)
+	}
+
+	// Replaced (as downloads do, by rename) with content of the same size and mtime: rehashed.
+	os.WriteFile(path+".tmp", []byte("weights-v2"), 0644)
+	os.Chtimes(path+".tmp", mtime, mtime)
+	os.Rename(path+".tmp", path)
+	stats, _ = weightDirStats(root)
+	got, _, err = measureWeightDir(root, stats, known, 2, &progressTracker{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inode := stats["pytorch_model.bin"].inode; inode != 0 && (got[0].Hash == "from-cache" || got[0].Hash == first[0].Hash) {
+		t.Fatalf("replaced file (inode %d) was not rehashed: %+v", inode, got)
+	}
+}
@@ -0,0 +1,416 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrReverifying is returned by CheckInference while a model whose files
+// changed on disk waits to be verified again.
+var ErrReverifying = errors.New("model files changed on disk; re-verifying")
+
+// watchSettle is how long a model's files must stay quiet before it is
+// re-verified, so a download in progress is measured once it finishes.
+const watchSettle = 2 * time.Second
+
+// watchTarget is a directory to watch and, if name is set, the one entry in it
+// that matters. An empty name means any change in the directory.
+type watchTarget struct {
+	dir  string
+	name string
+}
+
+// fsEvent is a change to name in dir. An empty dir means events were lost
+// (inotify queue overflow) and everything watched must be treated as changed.
+type fsEvent struct {
+	dir  string
+	name string
+}
+
+// dirWatcher reports changes to entries of the directories added to it.
+type dirWatcher interface {
+	add(dir string) error
+	remove(dir string)
+	close()
+}
+
+// watcher maps filesystem events on models' files to the aliases they affect.
+// Directories are watched with inotify where available and polled otherwise.
+type watcher struct {
+	events   chan fsEvent
+	notify   dirWatcher // nil without inotify
+	poll     *poller
+	onChange func(alias string)
+
+	mu      sync.Mutex
+	keys    map[string]string        // alias -> key its targets were computed for
+	targets map[string][]watchTarget // alias -> targets
+	refs    map[string]int           // dir -> aliases watching it
+	polled  map[string]bool          // dirs watched by polling
+}
+
+func newWatcher(onChange func(alias string)) *watcher {
+	w := &watcher{
+		events:   make(chan fsEvent, 256),
+		onChange: onChange,
+		keys:     make(map[string]string),
+		targets:  make(map[string][]watchTarget),
+		refs:     make(map[string]int),
+		polled:   make(map[string]bool),
+	}
+	w.poll = newPoller(w.events)
+	notify, err := newInotify(w.events)
+	if err != nil {
+		logger.Info("inotify unavailable; polling model directories", zap.Error(err))
+	} else {
+		w.notify = notify
+	}
+	return w
+}
+
+// run delivers events until ctx is done.
+func (w *watcher) run(ctx context.Context, pollInterval time.Duration) {
+	go w.poll.run(ctx, pollInterval)
+	defer func() {
+		if w.notify != nil {
+			w.notify.close()
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev := <-w.events:
+			for _, alias := range w.affected(ev) {
+				w.onChange(alias)
+			}
+		}
+	}
+}
+
+// set watches targets for alias. compute runs only when key differs from the
+// key alias was last registered with, so unchanged models cost a map lookup.
+func (w *watcher) set(alias, key string, compute func() []watchTarget) {
+	if w == nil {
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if k, ok := w.keys[alias]; ok && k == key {
+		return
+	}
+	targets := compute()
+	for _, t := range w.targets[alias] {
+		w.release(t.dir)
+	}
+	for _, t := range targets {
+		w.retain(t.dir)
+	}
+	w.keys[alias] = key
+	w.targets[alias] = targets
+}
+
+func (w *watcher) retain(dir string) {
+	w.refs[dir]++
+	if w.refs[dir] > 1 {
+		return
+	}
+	if w.notify != nil {
+		err := w.notify.add(dir)
+		if err == nil {
+			return
+		}
+		logger.Debug("inotify watch failed; polling instead", zap.String("dir", dir), zap.Error(err))
+	}
+	w.polled[dir] = true
+	w.poll.add(dir)
+}
+
+func (w *watcher) release(dir string) {
+	w.refs[dir]--
+	if w.refs[dir] > 0 {
+		return
+	}
+	delete(w.refs, dir)
+	if w.polled[dir] {
+		delete(w.polled, dir)
+		w.poll.remove(dir)
+	} else if w.notify != nil {
+		w.notify.remove(dir)
+	}
+}
+
+func (w *watcher) affected(ev fsEvent) []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var aliases []string
+	for alias, targets := range w.targets {
+		for _, t := range targets {
+			if ev.dir == "" || (t.dir == ev.dir && (t.name == "" || ev.name == "" || t.name == ev.name)) {
+				aliases = append(aliases, alias)
+				break
+			}
+		}
+	}
+	sort.Strings(aliases)
+	return aliases
+}
+
+// poller rescans directories for watchers without inotify.
+type poller struct {
+	events chan<- fsEvent
+
+	mu   sync.Mutex
+	dirs map[string]map[string]fileStat
+}
+
+func newPoller(events chan<- fsEvent) *poller {
+	return &poller{events: events, dirs: make(map[string]map[string]fileStat)}
+}
+
+func (p *poller) add(dir string) {
+	snap := scanDir(dir)
+	p.mu.Lock()
+	p.dirs[dir] = snap
+	p.mu.Unlock()
+}
+
+func (p *poller) remove(dir string) {
+	p.mu.Lock()
+	delete(p.dirs, dir)
+	p.mu.Unlock()
+}
+
+func (p *poller) run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		// Events are sent without p.mu held: the receiver may be adding dirs.
+		for _, ev := range p.scan() {
+			select {
+			case p.events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (p *poller) scan() []fsEvent {
+	p.mu.Lock()
+	dirs := make([]string, 0, len(p.dirs))
+	for dir := range p.dirs {
+		dirs = append(dirs, dir)
+	}
+	p.mu.Unlock()
+
+	var events []fsEvent
+	for _, dir := range dirs {
+		snap := scanDir(dir)
+		p.mu.Lock()
+		prev, ok := p.dirs[dir]
+		if ok {
+			p.dirs[dir] = snap
+		}
+		p.mu.Unlock()
+		if !ok {
+			continue // removed meanwhile
+		}
+		for name, st := range snap {
+			if old, ok := prev[name]; !ok || old != st {
+				events = append(events, fsEvent{dir: dir, name: name})
+			}
+		}
+		for name := range prev {
+			if _, ok := snap[name]; !ok {
+				events = append(events, fsEvent{dir: dir, name: name})
+			}
+		}
+	}
+	return events
+}
+
+// scanDir stats a directory's entries, following symlinks so a replaced link
+// target shows up as a change. A missing directory scans as empty.
+func scanDir(dir string) map[string]fileStat {
+	snap := make(map[string]fileStat)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return snap
+	}
+	for _, e := range entries {
+		info, err := os.Stat(filepath.Join(dir, e.Name()))
+		if err != nil {
+			if info, err = os.Lstat(filepath.Join(dir, e.Name())); err != nil {
+				continue
+			}
+		}
+		snap[e.Name()] = fileStat{size: info.Size(), modTime: info.ModTime().UnixNano(), inode: fileInode(info)}
+	}
+	return snap
+}
+
+// StartWatching re-verifies models as soon as their files change on disk
+// instead of waiting for the result cache to expire. A changed model's cached
+// results are dropped and CheckInference refuses it with ErrReverifying until
+// it has been verified again. Directories are registered as models are verified.
+func (v *Verifier) StartWatching(ctx context.Context, llmClient llm.Client, pollInterval time.Duration) {
+	w := newWatcher(func(alias string) { v.modelChanged(ctx, llmClient, alias) })
+	v.mu.Lock()
+	v.watcher = w
+	v.mu.Unlock()
+	go w.run(ctx, pollInterval)
+}
+
+// modelChanged evicts alias's cached results, blocks its inference and
+// schedules re-verification once its files have settled.
+func (v *Verifier) modelChanged(ctx context.Context, llmClient llm.Client, alias string) {
+	v.mu.Lock()
+	delete(v.resultCache, alias)
+	delete(v.cache, alias)
+	delete(v.attestCache, alias)
+	v.generation[alias]++
+	first := !v.reverifying[alias]
+	v.reverifying[alias] = true
+	if t, ok := v.reverifyTimers[alias]; ok {
+		t.Reset(v.settle)
+	} else {
+		v.reverifyTimers[alias] = time.AfterFunc(v.settle, func() { v.reverify(ctx, llmClient, alias) })
+	}
+	v.mu.Unlock()
+	v.syncReverifying(alias)
+
+	if first {
+		logger.Info("Model files changed on disk; blocking inference until re-verified", zap.String("alias", alias))
+	}
+}
+
+// reverify verifies alias again and lifts the inference block, unless its
+// files changed again meanwhile, in which case the pending timer retries.
+func (v *Verifier) reverify(ctx context.Context, llmClient llm.Client, alias string) {
+	if ctx.Err() != nil {
+		return
+	}
+	v.mu.Lock()
+	gen := v.generation[alias]
+	v.mu.Unlock()
+
+	models := v.ApplyToModels(ctx, llmClient, []llm.Model{{ID: alias, Object: "model", OwnedBy: v.serviceType}})
+
+	v.mu.Lock()
+	if v.generation[alias] != gen {
+		delete(v.resultCache, alias) // measured while still changing
+		v.mu.Unlock()
+		return
+	}
+	delete(v.reverifying, alias)
+	delete(v.reverifyTimers, alias)
+	v.mu.Unlock()
+	v.syncReverifying(alias)
+
+	logger.Info("Model re-verified after change on disk",
+		zap.String("alias", alias),
+		zap.String("verification_status", models[0].VerificationStatus))
+}
+
+// Reverifying returns the aliases blocked until re-verified, sorted.
+func (v *Verifier) Reverifying() []string {
+	if v == nil {
+		return nil
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	out := make([]string, 0, len(v.reverifying))
+	for alias := range v.reverifying {
+		out = append(out, alias)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (v *Verifier) currentWatcher() *watcher {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.watcher
+}
+
+// watchVLLM watches a vLLM weight root: every directory holding its files and,
+// for Hugging Face snapshots, the blob each file links to.
+func (v *Verifier) watchVLLM(alias, root string, stats map[string]fileStat) {
+	w := v.currentWatcher()
+	if w == nil {
+		return
+	}
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	key := root + "\n" + strings.Join(names, "\n")
+
+	w.set(alias, key, func() []watchTarget {
+		seen := make(map[watchTarget]bool)
+		var targets []watchTarget
+		addTarget := func(t watchTarget) {
+			if !seen[t] {
+				seen[t] = true
+				targets = append(targets, t)
+			}
+		}
+		addTarget(watchTarget{dir: root})
+		for _, name := range names {
+			path := filepath.Join(root, filepath.FromSlash(name))
+			addTarget(watchTarget{dir: filepath.Dir(path)})
+			if resolved, err := filepath.EvalSymlinks(path); err == nil && filepath.Dir(resolved) != filepath.Dir(path) {
+				addTarget(watchTarget{dir: filepath.Dir(resolved), name: filepath.Base(resolved)})
+			}
+		}
+		return targets
+	})
+}
+
+// watchOllama watches an Ollama model's manifest and its model blob.
+func (v *Verifier) watchOllama(alias, dir string) {
+	w := v.currentWatcher()
+	if w == nil {
+		return
+	}
+	name := ollamaModelName(alias)
+	manifest := ollamaManifestPath(dir, name)
+	key := manifest
+	if info, err := os.Stat(manifest); err == nil {
+		// A re-pulled model gets a new manifest and blob; watch the new blob.
+		key += fmt.Sprintf("\n%d/%d", info.Size(), info.ModTime().UnixNano())
+	}
+	w.set(alias, key, func() []watchTarget {
+		targets := []watchTarget{{dir: filepath.Dir(manifest), name: filepath.Base(manifest)}}
+		data, err := os.ReadFile(manifest)
+		if err != nil {
+			return targets
+		}
+		if layer, err := ollamaModelLayer(name, manifest, data); err == nil {
+			blob := ollamaBlobPath(dir, layer.Digest)
+			targets = append(targets, watchTarget{dir: filepath.Dir(blob), name: filepath.Base(blob)})
+		}
+		return targets
+	})
+}
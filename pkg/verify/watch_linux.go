@@ -0,0 +1,124 @@
+//go:build linux
+
+package verify
+
+import (
+	"bytes"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const inotifyMask = unix.IN_CREATE | unix.IN_DELETE | unix.IN_MODIFY | unix.IN_CLOSE_WRITE |
+	unix.IN_MOVED_FROM | unix.IN_MOVED_TO | unix.IN_ATTRIB | unix.IN_DELETE_SELF | unix.IN_MOVE_SELF
+
+// inotify watches directories with Linux inotify.
+type inotify struct {
+	fd     int
+	events chan<- fsEvent
+	done   chan struct{}
+
+	mu   sync.Mutex
+	wds  map[int32]string
+	dirs map[string]int32
+}
+
+func newInotify(events chan<- fsEvent) (dirWatcher, error) {
+	fd, err := unix.InotifyInit1(unix.IN_CLOEXEC | unix.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+	n := &inotify{
+		fd:     fd,
+		events: events,
+		done:   make(chan struct{}),
+		wds:    make(map[int32]string),
+		dirs:   make(map[string]int32),
+	}
+	go n.read()
+	return n, nil
+}
+
+func (n *inotify) add(dir string) error {
+	wd, err := unix.InotifyAddWatch(n.fd, dir, inotifyMask|unix.IN_ONLYDIR)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	n.wds[int32(wd)] = dir
+	n.dirs[dir] = int32(wd)
+	n.mu.Unlock()
+	return nil
+}
+
+func (n *inotify) remove(dir string) {
+	n.mu.Lock()
+	wd, ok := n.dirs[dir]
+	delete(n.dirs, dir)
+	delete(n.wds, wd)
+	n.mu.Unlock()
+	if ok {
+		unix.InotifyRmWatch(n.fd, uint32(wd))
+	}
+}
+
+func (n *inotify) close() {
+	close(n.done)
+}
+
+// read polls the non-blocking descriptor so close is noticed within 500ms.
+func (n *inotify) read() {
+	defer unix.Close(n.fd)
+	buf := make([]byte, 64<<10)
+	for {
+		select {
+		case <-n.done:
+			return
+		default:
+		}
+		size, err := unix.Read(n.fd, buf)
+		if err == unix.EAGAIN || err == unix.EINTR {
+			unix.Poll([]unix.PollFd{{Fd: int32(n.fd), Events: unix.POLLIN}}, 500)
+			continue
+		}
+		if err != nil || size <= 0 {
+			return
+		}
+		for _, ev := range n.parse(buf[:size]) {
+			select {
+			case n.events <- ev:
+			case <-n.done:
+				return
+			}
+		}
+	}
+}
+
+func (n *inotify) parse(buf []byte) []fsEvent {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	var events []fsEvent
+	for off := 0; off+unix.SizeofInotifyEvent <= len(buf); {
+		raw := (*unix.InotifyEvent)(unsafe.Pointer(&buf[off]))
+		nameStart := off + unix.SizeofInotifyEvent
+		nameEnd := nameStart + int(raw.Len)
+		if nameEnd > len(buf) {
+			break
+		}
+		name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+		off = nameEnd
+
+		switch {
+		case raw.Mask&unix.IN_Q_OVERFLOW != 0:
+			events = append(events, fsEvent{})
+		case raw.Mask&unix.IN_IGNORED != 0:
+			// watch removed
+		default:
+			if dir, ok := n.wds[raw.Wd]; ok {
+				events = append(events, fsEvent{dir: dir, name: name})
+			}
+		}
+	}
+	return events
+}
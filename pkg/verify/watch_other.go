@@ -0,0 +1,9 @@
+//go:build !linux
+
+package verify
+
+import "errors"
+
+func newInotify(events chan<- fsEvent) (dirWatcher, error) {
+	return nil, errors.New("inotify is only available on Linux")
+}
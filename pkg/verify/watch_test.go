@@ -0,0 +1,125 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+// waitFor polls cond until it holds or the deadline passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestWatchReverifiesChangedWeights(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"verification_status":"verified"}`))
+	}))
+	defer ts.Close()
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"config.json": "{}", "model.bin": "weights"})
+
+	const alias = "org/model"
+	v := NewVerifier(&Catalog{entries: map[string]CatalogEntry{alias: {ID: "1"}}}, NewServerClient(ts.URL, "k"), "vllm", "", root)
+	v.settle = 50 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v.StartWatching(ctx, nil, 50*time.Millisecond)
+
+	v.ApplyToModels(ctx, nil, []llm.Model{{ID: alias}})
+	if err := v.CheckInference(alias); err != nil || calls.Load() != 1 {
+		t.Fatalf("initial verification: %v, %d calls", err, calls.Load())
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "model.bin"), []byte("tampered"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "re-verification to start", func() bool { return len(v.Reverifying()) == 1 })
+	if err := v.CheckInference(alias); !errors.Is(err, ErrReverifying) {
+		t.Fatalf("blocked model: %v, want ErrReverifying", err)
+	}
+
+	waitFor(t, "re-verification to finish", func() bool { return len(v.Reverifying()) == 0 })
+	if calls.Load() < 2 {
+		t.Fatalf("platform called %d times; changed weights must be resubmitted", calls.Load())
+	}
+	if err := v.CheckInference(alias); err != nil {
+		t.Fatalf("after re-verification: %v", err)
+	}
+}
+
+func TestPollerReportsChanges(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"a": "1", "b": "2"})
+	p := newPoller(make(chan fsEvent))
+	p.add(dir)
+	if events := p.scan(); len(events) != 0 {
+		t.Fatalf("unchanged dir: %v", events)
+	}
+
+	os.WriteFile(filepath.Join(dir, "a"), []byte("longer"), 0644)
+	os.Remove(filepath.Join(dir, "b"))
+	os.WriteFile(filepath.Join(dir, "c"), []byte("3"), 0644)
+	got := make(map[string]bool)
+	for _, ev := range p.scan() {
+		if ev.dir != dir {
+			t.Fatalf("event for %s", ev.dir)
+		}
+		got[ev.name] = true
+	}
+	if len(got) != 3 || !got["a"] || !got["b"] || !got["c"] {
+		t.Fatalf("events for %v, want a, b and c", got)
+	}
+}
+
+func TestWatcherMatchesTargets(t *testing.T) {
+	w := &watcher{
+		poll:    newPoller(make(chan fsEvent)),
+		keys:    make(map[string]string),
+		targets: make(map[string][]watchTarget),
+		refs:    make(map[string]int),
+		polled:  make(map[string]bool),
+	}
+	w.set("a", "k", func() []watchTarget { return []watchTarget{{dir: "/m"}, {dir: "/blobs", name: "x"}} })
+	w.set("b", "k", func() []watchTarget { return []watchTarget{{dir: "/blobs", name: "y"}} })
+	w.set("a", "k", func() []watchTarget { t.Fatal("recomputed with an unchanged key"); return nil })
+
+	for ev, want := range map[fsEvent]string{
+		{dir: "/m", name: "any"}:   "a",
+		{dir: "/blobs", name: "y"}: "b",
+		{dir: "/blobs", name: "z"}: "",
+		{}:                         "a,b",
+	} {
+		got := ""
+		for i, alias := range w.affected(ev) {
+			if i > 0 {
+				got += ","
+			}
+			got += alias
+		}
+		if got != want {
+			t.Errorf("%+v affects %q, want %q", ev, got, want)
+		}
+	}
+	if w.refs["/blobs"] != 2 {
+		t.Fatalf("/blobs watched by %d aliases", w.refs["/blobs"])
+	}
+}
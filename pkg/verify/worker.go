@@ -0,0 +1,210 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// ErrVerificationPending is returned by CheckInference for an approved model
+// the background worker has not verified yet.
+var ErrVerificationPending = errors.New("model verification pending")
+
+// workerQueueSize bounds aliases waiting for the worker; further misses are
+// dropped and queued again by the next request.
+const workerQueueSize = 64
+
+// gate is one model's inference decision.
+type gate struct {
+	status      string
+	reason      string
+	checkedAt   time.Time
+	reverifying bool // files changed on disk; blocked until verified again
+}
+
+// allowlist is an immutable snapshot of inference decisions by alias. Writers
+// replace it whole, so CheckInference reads it without locking.
+type allowlist map[string]gate
+
+// CheckInference decides from the allowlist snapshot whether modelName may
+// serve inference. It never waits on the network or on hashing: a model not in
+// the snapshot yet, or whose decision is older than the result TTL, is queued
+// for the background worker (StartWorker).
+func (v *Verifier) CheckInference(modelName string) error {
+	var g gate
+	ok := false
+	if snap := v.allowed.Load(); snap != nil {
+		g, ok = (*snap)[modelName]
+	}
+	if ok && g.reverifying {
+		return fmt.Errorf("model %s: %w", modelName, ErrReverifying)
+	}
+	if !ok {
+		if _, approved := v.catalog.Get(modelName); !approved {
+			return fmt.Errorf("model %s is not verified (%s)", modelName, StatusUnverified)
+		}
+		v.enqueue(modelName)
+		return fmt.Errorf("model %s: %w", modelName, ErrVerificationPending)
+	}
+	if time.Since(g.checkedAt) >= verifyResultTTL {
+		v.enqueue(modelName) // serve the last decision meanwhile
+	}
+
+	status := g.status
+	if status == "" {
+		status = string(StatusUnverified)
+	}
+	if status == string(StatusPending) {
+		return fmt.Errorf("model %s: %w", modelName, ErrVerificationPending)
+	}
+	if !IsInferenceAllowed(status) {
+		if g.reason != "" {
+			return fmt.Errorf("model %s is not verified (%s: %s)", modelName, status, g.reason)
+		}
+		return fmt.Errorf("model %s is not verified (%s)", modelName, status)
+	}
+	return nil
+}
+
+// StartWorker verifies queued models in the background and re-verifies all
+// local models every interval, pruning models no longer served.
+func (v *Verifier) StartWorker(ctx context.Context, llmClient llm.Client, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultRefreshInterval
+	}
+	go v.runWorker(ctx, llmClient, interval)
+}
+
+func (v *Verifier) runWorker(ctx context.Context, llmClient llm.Client, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case alias := <-v.queue:
+			v.verifyQueued(ctx, llmClient, alias)
+		case <-ticker.C:
+			v.refreshAllowlist(ctx, llmClient)
+		}
+	}
+}
+
+// verifyQueued verifies alias and any other queued aliases in one batch, so
+// Ollama tags are listed once.
+func (v *Verifier) verifyQueued(ctx context.Context, llmClient llm.Client, alias string) {
+	models := []llm.Model{{ID: alias, Object: "model", OwnedBy: v.serviceType}}
+	for more := true; more; {
+		select {
+		case next := <-v.queue:
+			models = append(models, llm.Model{ID: next, Object: "model", OwnedBy: v.serviceType})
+		default:
+			more = false
+		}
+	}
+	v.ApplyToModels(ctx, llmClient, models)
+	for _, m := range models {
+		v.queued.Delete(m.ID)
+	}
+}
+
+// refreshAllowlist re-verifies every model the backend serves and replaces
+// the allowlist with the results. When the backend cannot be listed the
+// current allowlist is kept.
+func (v *Verifier) refreshAllowlist(ctx context.Context, llmClient llm.Client) {
+	resp, err := llmClient.ListModels(ctx)
+	if err != nil {
+		logger.Warn("Failed to list models for verification refresh", zap.Error(err))
+		return
+	}
+	models, inconclusive := v.verifyModels(ctx, llmClient, resp.Models)
+	v.publish(models, inconclusive, true)
+}
+
+func (v *Verifier) enqueue(alias string) {
+	if _, loaded := v.queued.LoadOrStore(alias, struct{}{}); loaded {
+		return
+	}
+	select {
+	case v.queue <- alias:
+	default:
+		v.queued.Delete(alias)
+	}
+}
+
+// publish records models' verification results in the allowlist. With prune,
+// aliases not among models are dropped unless they are being re-verified. An
+// inconclusive result does not replace a decision already made, unless the
+// model's files changed since; it only creates a pending gate.
+func (v *Verifier) publish(models []llm.Model, inconclusive map[string]bool, prune bool) {
+	now := time.Now()
+	v.updateAllowlist(func(next allowlist, reverifying map[string]bool) {
+		previous := make(allowlist, len(next))
+		for alias, g := range next {
+			previous[alias] = g
+		}
+		if prune {
+			for alias := range next {
+				if !reverifying[alias] {
+					delete(next, alias)
+				}
+			}
+		}
+		for _, m := range models {
+			if g, ok := previous[m.ID]; ok && inconclusive[m.ID] && !g.reverifying && g.status != "" {
+				next[m.ID] = g
+				continue
+			}
+			next[m.ID] = gate{
+				status:      m.VerificationStatus,
+				reason:      m.VerificationReason,
+				checkedAt:   now,
+				reverifying: reverifying[m.ID],
+			}
+		}
+	})
+}
+
+// syncReverifying copies alias's re-verification flag into the allowlist. A
+// gate that only held the flag, with no result, is dropped once it clears so
+// the alias is verified like any other miss.
+func (v *Verifier) syncReverifying(alias string) {
+	v.updateAllowlist(func(next allowlist, reverifying map[string]bool) {
+		g := next[alias]
+		g.reverifying = reverifying[alias]
+		if !g.reverifying && g.status == "" {
+			delete(next, alias)
+			return
+		}
+		next[alias] = g
+	})
+}
+
+// updateAllowlist applies update to a copy of the allowlist and stores it.
+// Writers are serialized, and the re-verification flags are read while the
+// writer lock is held so a stale flag never overwrites a newer one.
+func (v *Verifier) updateAllowlist(update func(next allowlist, reverifying map[string]bool)) {
+	v.allowMu.Lock()
+	defer v.allowMu.Unlock()
+
+	v.mu.Lock()
+	reverifying := make(map[string]bool, len(v.reverifying))
+	for alias := range v.reverifying {
+		reverifying[alias] = true
+	}
+	v.mu.Unlock()
+
+	next := make(allowlist)
+	if cur := v.allowed.Load(); cur != nil {
+		for alias, g := range *cur {
+			next[alias] = g
+		}
+	}
+	update(next, reverifying)
+	v.allowed.Store(&next)
+}
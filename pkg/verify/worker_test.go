@@ -0,0 +1,133 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sentnl/inferoute-node/inferoute-client/pkg/llm"
+)
+
+// listClient is an llm.Client that only lists models.
+type listClient struct {
+	llm.Client
+	models []llm.Model
+}
+
+func (c *listClient) ListModels(ctx context.Context) (*llm.ListModelsResponse, error) {
+	return &llm.ListModelsResponse{Object: "list", Models: c.models}, nil
+}
+
+func TestCheckInferenceQueuesMisses(t *testing.T) {
+	var calls atomic.Int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Write([]byte(`{"verification_status":"verified"}`))
+	}))
+	defer ts.Close()
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"config.json": "{}", "model.bin": "weights"})
+	const alias = "org/model"
+	v := NewVerifier(&Catalog{entries: map[string]CatalogEntry{alias: {ID: "1"}}}, NewServerClient(ts.URL, "k"), "vllm", "", root)
+
+	if err := v.CheckInference("org/unapproved"); err == nil || errors.Is(err, ErrVerificationPending) {
+		t.Fatalf("unapproved model: %v, want rejected without queueing", err)
+	}
+	if err := v.CheckInference(alias); !errors.Is(err, ErrVerificationPending) {
+		t.Fatalf("first request: %v, want ErrVerificationPending", err)
+	}
+	if calls.Load() != 0 {
+		t.Fatal("request path reached the platform")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v.StartWorker(ctx, nil, time.Hour)
+	waitFor(t, "background verification", func() bool { return v.CheckInference(alias) == nil })
+	if calls.Load() != 1 {
+		t.Fatalf("platform called %d times, want 1", calls.Load())
+	}
+}
+
+func TestCheckInferenceServesStaleDecision(t *testing.T) {
+	v := NewVerifier(&Catalog{entries: map[string]CatalogEntry{}}, nil, "vllm", "", "")
+	v.allowed.Store(&allowlist{
+		"stale":  {status: string(StatusVerified), checkedAt: time.Now().Add(-verifyResultTTL - time.Second)},
+		"failed": {status: string(StatusFailed), reason: ReasonBlobMismatch, checkedAt: time.Now()},
+	})
+
+	if err := v.CheckInference("stale"); err != nil {
+		t.Fatalf("stale verified model: %v", err)
+	}
+	if _, ok := v.queued.Load("stale"); !ok {
+		t.Fatal("stale decision not queued for re-verification")
+	}
+	if err := v.CheckInference("failed"); err == nil || errors.Is(err, ErrVerificationPending) {
+		t.Fatalf("failed model: %v", err)
+	}
+}
+
+func TestRefreshAllowlistPrunes(t *testing.T) {
+	v := NewVerifier(&Catalog{entries: map[string]CatalogEntry{}}, nil, "vllm", "", "")
+	v.publish([]llm.Model{{ID: "kept"}, {ID: "removed"}, {ID: "changed"}}, nil, false)
+	v.reverifying["changed"] = true
+	v.syncReverifying("changed")
+
+	v.refreshAllowlist(context.Background(), &listClient{models: []llm.Model{{ID: "kept"}}})
+	snap := *v.allowed.Load()
+	if _, ok := snap["removed"]; ok {
+		t.Fatal("model no longer served kept in allowlist")
+	}
+	if g, ok := snap["kept"]; !ok || g.status != string(StatusUnverified) {
+		t.Fatalf("kept: %+v, %v", g, ok)
+	}
+	if !snap["changed"].reverifying {
+		t.Fatal("model being re-verified dropped from allowlist")
+	}
+}
+
+func TestInconclusiveResultsKeepDecisions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{"config.json": "{}", "model.bin": "weights"})
+	const alias = "org/model"
+	v := NewVerifier(&Catalog{entries: map[string]CatalogEntry{alias: {ID: "1"}, "org/changed": {ID: "2"}}}, NewServerClient(ts.URL, "k"), "vllm", "", root)
+	v.allowed.Store(&allowlist{alias: {status: string(StatusVerified), checkedAt: time.Now()}})
+
+	// The verify server being down does not revoke a decision already made
+	models := v.ApplyToModels(context.Background(), nil, []llm.Model{{ID: alias}})
+	if models[0].VerificationStatus != string(StatusPending) {
+		t.Fatalf("status = %q, want pending", models[0].VerificationStatus)
+	}
+	if err := v.CheckInference(alias); err != nil {
+		t.Fatalf("verified model blocked by a verify-server error: %v", err)
+	}
+
+	// Without one, the model waits for a result instead of being rejected
+	v.allowed.Store(&allowlist{})
+	v.ApplyToModels(context.Background(), nil, []llm.Model{{ID: alias}})
+	if err := v.CheckInference(alias); !errors.Is(err, ErrVerificationPending) {
+		t.Fatalf("first check failed with an error: %v, want ErrVerificationPending", err)
+	}
+
+	// A gate that only carried the re-verification flag goes when it clears
+	v.reverifying["org/changed"] = true
+	v.syncReverifying("org/changed")
+	delete(v.reverifying, "org/changed")
+	v.syncReverifying("org/changed")
+	if _, ok := (*v.allowed.Load())["org/changed"]; ok {
+		t.Fatal("empty gate kept after re-verification flag cleared")
+	}
+	if err := v.CheckInference("org/changed"); !errors.Is(err, ErrVerificationPending) {
+		t.Fatalf("after re-verification flag cleared: %v, want ErrVerificationPending", err)
+	}
+}
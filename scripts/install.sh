@@ -289,6 +289,7 @@ fi
 
 LLM_URL=$(check_env_var "LLM_URL" "$LLM_URL" "$DEFAULT_LLM_URL")
 SERVER_PORT=$(check_env_var "SERVER_PORT" "$SERVER_PORT" "8080")
+OLLAMA_MODELS=$(check_env_var "OLLAMA_MODELS" "$OLLAMA_MODELS" "")
 
 # Verify required configuration
 if [ -z "$PROVIDER_API_KEY" ]; then
@@ -309,12 +310,18 @@ if [ "$(uname)" = "Darwin" ]; then
     sed -i '' "s|api_key: .*|api_key: \"$PROVIDER_API_KEY\"|" "$CONFIG_DIR/config.yaml"
     sed -i '' "s|provider_type: .*|provider_type: \"$PROVIDER_TYPE\"|" "$CONFIG_DIR/config.yaml"
     sed -i '' "s|llm_url: .*|llm_url: \"$LLM_URL\"|" "$CONFIG_DIR/config.yaml"
+    if [ -n "$OLLAMA_MODELS" ]; then
+        sed -i '' "s|# ollama_models: .*|ollama_models: \"$OLLAMA_MODELS\"|" "$CONFIG_DIR/config.yaml"
+    fi
 else
     # Linux version
     sed -i "s|port: .*|port: $SERVER_PORT|" "$CONFIG_DIR/config.yaml"
     sed -i "s|api_key: .*|api_key: \"$PROVIDER_API_KEY\"|" "$CONFIG_DIR/config.yaml"
     sed -i "s|provider_type: .*|provider_type: \"$PROVIDER_TYPE\"|" "$CONFIG_DIR/config.yaml"
     sed -i "s|llm_url: .*|llm_url: \"$LLM_URL\"|" "$CONFIG_DIR/config.yaml"
+    if [ -n "$OLLAMA_MODELS" ]; then
+        sed -i "s|# ollama_models: .*|ollama_models: \"$OLLAMA_MODELS\"|" "$CONFIG_DIR/config.yaml"
+    fi
 fi
 
 echo -e "${GREEN}Configuration file updated successfully.${NC}"